@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AuditEntry records a single action taken through the admin API.
+type AuditEntry struct {
+	// Time is when the action was taken.
+	Time time.Time
+
+	// Action identifies what was done, e.g. "revoke" or "introspect".
+	Action string
+
+	// IDHash is the hex-encoded identifier hash of the LSAT the action
+	// was taken against, if applicable.
+	IDHash string
+
+	// Remote is the address the request originated from.
+	Remote string
+}
+
+// AuditLog persists a trail of every action taken through the admin API, so
+// operators can later answer "who revoked this token, and when".
+type AuditLog interface {
+	// Append records a new audit entry.
+	Append(ctx context.Context, entry AuditEntry) error
+
+	// Entries returns every audit entry recorded so far, oldest first.
+	Entries(ctx context.Context) ([]AuditEntry, error)
+}
+
+// MemoryAuditLog is an AuditLog that keeps all entries in memory. It's the
+// default used when no persistent backing is configured.
+type MemoryAuditLog struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+// NewMemoryAuditLog creates a new, empty MemoryAuditLog.
+func NewMemoryAuditLog() *MemoryAuditLog {
+	return &MemoryAuditLog{}
+}
+
+func (l *MemoryAuditLog) Append(_ context.Context, entry AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *MemoryAuditLog) Entries(_ context.Context) ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]AuditEntry, len(l.entries))
+	copy(out, l.entries)
+	return out, nil
+}
@@ -0,0 +1,13 @@
+package admin
+
+import "errors"
+
+// errAdminAuthRequired is returned when a request to the admin API does not
+// carry a valid admin macaroon.
+var errAdminAuthRequired = errors.New("a valid admin macaroon is required")
+
+// ErrNoAuthConfigured is returned by NewServer when its AuthConfig has
+// neither an admin macaroon nor mTLS configured, which would otherwise leave
+// the admin API reachable without authentication.
+var ErrNoAuthConfigured = errors.New("admin API requires AdminRootKey or " +
+	"RequireClientCert to be configured")
@@ -0,0 +1,162 @@
+package admin
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightninglabs/aperture/mint"
+)
+
+// TokenRecord is the introspection-friendly view of an LSAT tracked by the
+// admin subsystem: everything an operator needs to see without having to
+// decode a macaroon themselves.
+type TokenRecord struct {
+	mint.TokenInfo
+
+	// Revoked is true once the token's secret has been revoked through
+	// the admin API (or otherwise).
+	Revoked bool
+}
+
+// ListFilter narrows down the results returned by Registry.List.
+type ListFilter struct {
+	// Service, if non-empty, restricts the results to tokens authorized
+	// for the given service.
+	Service string
+
+	// RevokedOnly, if true, restricts the results to revoked tokens.
+	RevokedOnly bool
+}
+
+// Registry tracks every LSAT minted by the server so it can be introspected
+// and revoked through the admin API. It is kept separate from
+// mint.SecretStore because it stores richer, non-sensitive metadata (mint
+// time, services, revocation status) rather than just the raw secret.
+type Registry interface {
+	// Put records a newly minted token.
+	Put(ctx context.Context, record *TokenRecord) error
+
+	// Get returns the record for the given identifier hash.
+	Get(ctx context.Context, idHash [sha256.Size]byte) (*TokenRecord, error)
+
+	// List returns every record matching filter, ordered by mint time.
+	List(ctx context.Context, filter ListFilter) ([]*TokenRecord, error)
+
+	// MarkRevoked flags the record for the given identifier hash as
+	// revoked.
+	MarkRevoked(ctx context.Context, idHash [sha256.Size]byte) error
+}
+
+// MemoryRegistry is a Registry that keeps all records in memory. It is the
+// default used when no persistent backing is configured.
+type MemoryRegistry struct {
+	mu      sync.RWMutex
+	records map[[sha256.Size]byte]*TokenRecord
+	order   [][sha256.Size]byte
+}
+
+// NewMemoryRegistry creates a new, empty MemoryRegistry.
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		records: make(map[[sha256.Size]byte]*TokenRecord),
+	}
+}
+
+// RecordToken implements mint.Recorder, allowing a MemoryRegistry to be
+// wired directly into mint.Config.Recorder.
+func (r *MemoryRegistry) RecordToken(ctx context.Context, info *mint.TokenInfo) error {
+	return r.Put(ctx, &TokenRecord{TokenInfo: *info})
+}
+
+func (r *MemoryRegistry) Put(_ context.Context, record *TokenRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.records[record.IDHash]; !exists {
+		r.order = append(r.order, record.IDHash)
+	}
+	r.records[record.IDHash] = record
+	return nil
+}
+
+func (r *MemoryRegistry) Get(_ context.Context,
+	idHash [sha256.Size]byte) (*TokenRecord, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.records[idHash]
+	if !ok {
+		return nil, fmt.Errorf("no token found for id hash %x: %w",
+			idHash, mint.ErrSecretNotFound)
+	}
+
+	return record, nil
+}
+
+func (r *MemoryRegistry) List(_ context.Context,
+	filter ListFilter) ([]*TokenRecord, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]*TokenRecord, 0, len(r.order))
+	for _, idHash := range r.order {
+		record := r.records[idHash]
+
+		if filter.RevokedOnly && !record.Revoked {
+			continue
+		}
+		if filter.Service != "" && !hasService(record.Services, filter.Service) {
+			continue
+		}
+
+		results = append(results, record)
+	}
+
+	return results, nil
+}
+
+func (r *MemoryRegistry) MarkRevoked(_ context.Context, idHash [sha256.Size]byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[idHash]
+	if !ok {
+		return fmt.Errorf("no token found for id hash %x: %w",
+			idHash, mint.ErrSecretNotFound)
+	}
+
+	record.Revoked = true
+	return nil
+}
+
+// ListByKeyID implements mint.KeyedSecretLister, allowing a MemoryRegistry
+// to be swept for tokens minted under a retired signing key version.
+func (r *MemoryRegistry) ListByKeyID(_ context.Context,
+	keyID uint32) ([][sha256.Size]byte, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var idHashes [][sha256.Size]byte
+	for _, idHash := range r.order {
+		if r.records[idHash].Identifier.KeyID == keyID {
+			idHashes = append(idHashes, idHash)
+		}
+	}
+
+	return idHashes, nil
+}
+
+func hasService(services []lsat.Service, name string) bool {
+	for _, service := range services {
+		if service.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,308 @@
+// Package admin implements an HTTP API, separate from the main proxy
+// listener, that lets an operator manage the lifecycle of LSATs issued by
+// the mint: introspecting them, listing them, and revoking ones that have
+// leaked or are being abused.
+package admin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lightninglabs/aperture/mint"
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+// Config configures a Server.
+type Config struct {
+	// Addr is the address the admin API listens on. It is expected to be
+	// a different address than the main proxy listener so that it can be
+	// firewalled off separately.
+	Addr string
+
+	// Auth configures how requests to the admin API are authenticated.
+	Auth AuthConfig
+
+	// Secrets is the same secret store backing the mint, used to
+	// actually revoke an LSAT's secret.
+	Secrets mint.SecretStore
+
+	// Registry tracks metadata about every LSAT minted, for
+	// introspection and listing.
+	Registry Registry
+
+	// Audit records every action taken through the admin API.
+	Audit AuditLog
+}
+
+// Server is the admin HTTP API.
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+	srv *http.Server
+}
+
+// NewServer creates a new admin Server from cfg. It returns
+// ErrNoAuthConfigured if cfg.Auth has neither an admin macaroon nor mTLS
+// configured, since an admin API reachable without authentication would let
+// anyone revoke or introspect tokens.
+func NewServer(cfg Config) (*Server, error) {
+	if len(cfg.Auth.AdminRootKey) == 0 && !cfg.Auth.RequireClientCert {
+		return nil, ErrNoAuthConfigured
+	}
+
+	if cfg.Registry == nil {
+		cfg.Registry = NewMemoryRegistry()
+	}
+	if cfg.Audit == nil {
+		cfg.Audit = NewMemoryAuditLog()
+	}
+
+	s := &Server{
+		cfg: cfg,
+		mux: http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/v1/admin/lsat/revoke", s.handleRevoke)
+	s.mux.HandleFunc("/v1/admin/lsat/", s.handleIntrospect)
+	s.mux.HandleFunc("/v1/admin/lsat", s.handleList)
+
+	s.srv = &http.Server{
+		Addr:    cfg.Addr,
+		Handler: authMiddleware(cfg.Auth, s.mux),
+	}
+
+	return s, nil
+}
+
+// Handler returns the (authenticated) HTTP handler backing the admin API,
+// primarily so it can be exercised in tests via httptest.NewServer without
+// binding a real listener.
+func (s *Server) Handler() http.Handler {
+	return s.srv.Handler
+}
+
+// Start begins serving the admin API on its own listener. It blocks until
+// the server is shut down or fails.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %v", s.cfg.Addr, err)
+	}
+	return s.srv.Serve(listener)
+}
+
+// Stop gracefully shuts down the admin API.
+func (s *Server) Stop(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// revokeRequest is the body accepted by POST /v1/admin/lsat/revoke. At least
+// one of IDHash or Macaroon must be set; if both are, IDHash is used and
+// Macaroon is ignored.
+type revokeRequest struct {
+	// IDHash is the hex-encoded identifier hash of the token to revoke.
+	IDHash string `json:"id_hash,omitempty"`
+
+	// Macaroon is the hex-encoded serialized macaroon of the token to
+	// revoke. Its identifier hash is derived from it.
+	Macaroon string `json:"macaroon,omitempty"`
+}
+
+func (s *Server) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req revokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	idHash, err := resolveIDHash(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if err := s.cfg.Secrets.RevokeSecret(ctx, idHash); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Best-effort: the secret has already been revoked above, which is
+	// the part that actually matters for security; a registry miss just
+	// means we can't show this token in introspection anymore, so it
+	// isn't fatal to the request.
+	_ = s.cfg.Registry.MarkRevoked(ctx, idHash)
+
+	s.audit(ctx, "revoke", idHash, r)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// resolveIDHash determines the identifier hash to revoke/introspect from a
+// revokeRequest, accepting either the hash directly or a serialized
+// macaroon to derive it from.
+func resolveIDHash(req revokeRequest) ([sha256.Size]byte, error) {
+	var idHash [sha256.Size]byte
+
+	switch {
+	case req.IDHash != "":
+		raw, err := hex.DecodeString(req.IDHash)
+		if err != nil || len(raw) != sha256.Size {
+			return idHash, fmt.Errorf("invalid id_hash")
+		}
+		copy(idHash[:], raw)
+		return idHash, nil
+
+	case req.Macaroon != "":
+		raw, err := hex.DecodeString(req.Macaroon)
+		if err != nil {
+			return idHash, fmt.Errorf("invalid macaroon encoding")
+		}
+		var mac macaroon.Macaroon
+		if err := mac.UnmarshalBinary(raw); err != nil {
+			return idHash, fmt.Errorf("invalid macaroon")
+		}
+		return sha256.Sum256(mac.Id()), nil
+
+	default:
+		return idHash, fmt.Errorf("one of id_hash or macaroon is required")
+	}
+}
+
+// tokenView is the JSON representation of a TokenRecord returned by the
+// introspection and listing endpoints.
+type tokenView struct {
+	IDHash      string   `json:"id_hash"`
+	Services    []string `json:"services,omitempty"`
+	MintTime    string   `json:"mint_time"`
+	PaymentHash string   `json:"payment_hash"`
+	Revoked     bool     `json:"revoked"`
+}
+
+func newTokenView(record *TokenRecord) tokenView {
+	services := make([]string, 0, len(record.Services))
+	for _, service := range record.Services {
+		services = append(services, service.Name)
+	}
+
+	return tokenView{
+		IDHash:      hex.EncodeToString(record.IDHash[:]),
+		Services:    services,
+		MintTime:    record.MintTime.UTC().Format("2006-01-02T15:04:05Z"),
+		PaymentHash: record.Identifier.PaymentHash.String(),
+		Revoked:     record.Revoked,
+	}
+}
+
+func (s *Server) handleIntrospect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	const prefix = "/v1/admin/lsat/"
+	raw, err := hex.DecodeString(r.URL.Path[len(prefix):])
+	if err != nil || len(raw) != sha256.Size {
+		http.Error(w, "invalid token id", http.StatusBadRequest)
+		return
+	}
+	var idHash [sha256.Size]byte
+	copy(idHash[:], raw)
+
+	ctx := r.Context()
+	record, err := s.cfg.Registry.Get(ctx, idHash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	s.audit(ctx, "introspect", idHash, r)
+
+	writeJSON(w, newTokenView(record))
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter := ListFilter{
+		Service:     r.URL.Query().Get("service"),
+		RevokedOnly: r.URL.Query().Get("revoked") == "true",
+	}
+
+	ctx := r.Context()
+	records, err := s.cfg.Registry.List(ctx, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && v > 0 {
+		offset = v
+	}
+
+	if offset > len(records) {
+		offset = len(records)
+	}
+	end := offset + limit
+	if end > len(records) {
+		end = len(records)
+	}
+	page := records[offset:end]
+
+	views := make([]tokenView, 0, len(page))
+	for _, record := range page {
+		views = append(views, newTokenView(record))
+	}
+
+	s.audit(ctx, "list", [sha256.Size]byte{}, r)
+
+	writeJSON(w, struct {
+		Tokens []tokenView `json:"tokens"`
+		Total  int         `json:"total"`
+	}{Tokens: views, Total: len(records)})
+}
+
+func (s *Server) audit(ctx context.Context, action string,
+	idHash [sha256.Size]byte, r *http.Request) {
+
+	if s.cfg.Audit == nil {
+		return
+	}
+
+	entry := AuditEntry{
+		Time:   time.Now(),
+		Action: action,
+		Remote: r.RemoteAddr,
+	}
+	if idHash != ([sha256.Size]byte{}) {
+		entry.IDHash = hex.EncodeToString(idHash[:])
+	}
+
+	// Best-effort: a failure to persist an audit entry shouldn't block
+	// the admin action itself from completing.
+	_ = s.cfg.Audit.Append(ctx, entry)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
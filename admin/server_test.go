@@ -0,0 +1,139 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightninglabs/aperture/mint"
+	"github.com/lightninglabs/aperture/store"
+	"github.com/lightningnetwork/lnd/lntypes"
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+// mockChallenger is a minimal mint.Challenger that doesn't require talking
+// to an actual lnd node.
+type mockChallenger struct{}
+
+func (mockChallenger) NewChallenge(int64) (string, lntypes.Hash, error) {
+	return "lnbc1...mockinvoice", lntypes.Hash{1, 2, 3}, nil
+}
+
+// mockServiceLimiter is a minimal mint.ServiceLimiter that attaches no
+// additional caveats.
+type mockServiceLimiter struct{}
+
+func (mockServiceLimiter) ServiceCapabilities(context.Context, ...lsat.Service) ([]lsat.Caveat, error) {
+	return nil, nil
+}
+
+func (mockServiceLimiter) ServiceTimeouts(context.Context, ...lsat.Service) ([]lsat.Caveat, error) {
+	return nil, nil
+}
+
+// TestRevokeLSATViaAdminAPI mints an LSAT, revokes it through the admin
+// HTTP API, and confirms that the mint subsequently refuses to verify it.
+func TestRevokeLSATViaAdminAPI(t *testing.T) {
+	ctx := context.Background()
+
+	secrets := store.NewMemoryStore()
+	registry := NewMemoryRegistry()
+
+	m := mint.New(&mint.Config{
+		Secrets:        secrets,
+		Challenger:     mockChallenger{},
+		ServiceLimiter: mockServiceLimiter{},
+		Recorder:       registry,
+	})
+
+	testService := lsat.Service{Name: "loop", Tier: lsat.BaseTier}
+	mac, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT: %v", err)
+	}
+
+	params := &mint.VerificationParams{
+		Macaroon:      mac,
+		TargetService: testService.Name,
+	}
+	if err := m.VerifyLSAT(ctx, params); err != nil {
+		t.Fatalf("unable to verify freshly minted LSAT: %v", err)
+	}
+
+	adminRootKey := []byte("admin-root-key-for-testing-only")
+	server, err := NewServer(Config{
+		Auth:     AuthConfig{AdminRootKey: adminRootKey},
+		Secrets:  secrets,
+		Registry: registry,
+		Audit:    NewMemoryAuditLog(),
+	})
+	if err != nil {
+		t.Fatalf("unable to create admin server: %v", err)
+	}
+
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	adminMac, err := macaroon.New(
+		adminRootKey, []byte("admin"), "aperture-admin",
+		macaroon.LatestVersion,
+	)
+	if err != nil {
+		t.Fatalf("unable to mint admin macaroon: %v", err)
+	}
+
+	macBytes, err := adminMac.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unable to serialize admin macaroon: %v", err)
+	}
+
+	idHash := sha256.Sum256(mac.Id())
+	reqBody, err := json.Marshal(map[string]string{
+		"id_hash": hex.EncodeToString(idHash[:]),
+	})
+	if err != nil {
+		t.Fatalf("unable to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest(
+		http.MethodPost, httpServer.URL+"/v1/admin/lsat/revoke",
+		bytes.NewReader(reqBody),
+	)
+	if err != nil {
+		t.Fatalf("unable to build request: %v", err)
+	}
+	req.Header.Set("Authorization", "Macaroon "+hex.EncodeToString(macBytes))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unable to send revoke request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 OK from revoke, got %d", resp.StatusCode)
+	}
+
+	if err := m.VerifyLSAT(ctx, params); err != mint.ErrSecretNotFound {
+		t.Fatalf("expected ErrSecretNotFound after revocation, got %v", err)
+	}
+}
+
+// TestNewServerRejectsUnauthenticatedConfig ensures that NewServer refuses
+// to start an admin API that would otherwise be reachable without any
+// authentication at all.
+func TestNewServerRejectsUnauthenticatedConfig(t *testing.T) {
+	_, err := NewServer(Config{
+		Secrets:  store.NewMemoryStore(),
+		Registry: NewMemoryRegistry(),
+	})
+	if err != ErrNoAuthConfigured {
+		t.Fatalf("expected ErrNoAuthConfigured, got %v", err)
+	}
+}
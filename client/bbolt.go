@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	bbolt "go.etcd.io/bbolt"
+)
+
+// bboltDBFileName is the name of the database file created within the
+// configured directory.
+const bboltDBFileName = "aperture_client_tokens.db"
+
+// tokensBucket is the name of the top-level bbolt bucket holding tokens.
+var tokensBucket = []byte("tokens")
+
+// BboltStore is a Store backed by a local bbolt database, suitable for
+// long-running clients that want their tokens to survive a restart.
+type BboltStore struct {
+	db *bbolt.DB
+}
+
+// NewBboltStore opens (creating if necessary) a bbolt database within dir
+// and returns a BboltStore backed by it.
+func NewBboltStore(dir string) (*BboltStore, error) {
+	dbPath := filepath.Join(dir, bboltDBFileName)
+	db, err := bbolt.Open(dbPath, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open bbolt db: %v", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(tokensBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create bucket: %v", err)
+	}
+
+	return &BboltStore{db: db}, nil
+}
+
+// Close releases the underlying bbolt database file.
+func (s *BboltStore) Close() error {
+	return s.db.Close()
+}
+
+// CurrentToken returns the token currently stored for target.
+func (s *BboltStore) CurrentToken(_ context.Context,
+	target string) (*TokenEntry, error) {
+
+	var entry *TokenEntry
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(tokensBucket)
+		v := bucket.Get([]byte(target))
+		if v == nil {
+			return ErrNoToken
+		}
+
+		entry = &TokenEntry{}
+		return json.Unmarshal(v, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// PutToken stores entry as the current token for target.
+func (s *BboltStore) PutToken(_ context.Context, target string,
+	entry *TokenEntry) error {
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to encode token: %v", err)
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(tokensBucket)
+		return bucket.Put([]byte(target), raw)
+	})
+}
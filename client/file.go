@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileStoreFileName is the name of the file a FileStore persists its tokens
+// to within its configured directory.
+const fileStoreFileName = "aperture_tokens.json"
+
+// FileStore is a Store backed by a single JSON file, suitable for simple
+// command-line clients that want their tokens to survive a restart without
+// needing a database.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore creates a FileStore that persists its tokens to a file
+// within dir, creating the file if it doesn't already exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	path := filepath.Join(dir, fileStoreFileName)
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := writeTokens(path, make(map[string]*TokenEntry)); err != nil {
+			return nil, fmt.Errorf("unable to initialize token "+
+				"file: %v", err)
+		}
+	}
+
+	return &FileStore{path: path}, nil
+}
+
+// CurrentToken returns the token currently stored for target.
+func (s *FileStore) CurrentToken(_ context.Context,
+	target string) (*TokenEntry, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := readTokens(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := tokens[target]
+	if !ok {
+		return nil, ErrNoToken
+	}
+
+	return entry, nil
+}
+
+// PutToken stores entry as the current token for target.
+func (s *FileStore) PutToken(_ context.Context, target string,
+	entry *TokenEntry) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := readTokens(s.path)
+	if err != nil {
+		return err
+	}
+
+	tokens[target] = entry
+	return writeTokens(s.path, tokens)
+}
+
+func readTokens(path string) (map[string]*TokenEntry, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token file: %v", err)
+	}
+
+	tokens := make(map[string]*TokenEntry)
+	if len(raw) == 0 {
+		return tokens, nil
+	}
+	if err := json.Unmarshal(raw, &tokens); err != nil {
+		return nil, fmt.Errorf("unable to parse token file: %v", err)
+	}
+
+	return tokens, nil
+}
+
+func writeTokens(path string, tokens map[string]*TokenEntry) error {
+	raw, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("unable to encode tokens: %v", err)
+	}
+
+	return os.WriteFile(path, raw, 0600)
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MacaroonMetadataKey is the gRPC metadata key a paid LSAT's macaroon is
+// presented in.
+const MacaroonMetadataKey = "macaroon"
+
+// PreimageMetadataKey is the gRPC metadata key a paid LSAT's preimage is
+// presented in.
+const PreimageMetadataKey = "preimage"
+
+// authHeaderMetadataKey is the metadata key Aperture's gRPC gateway
+// forwards a server's WWW-Authenticate challenge header under; gRPC
+// lower-cases all metadata keys.
+var authHeaderMetadataKey = strings.ToLower(lsat.AuthHeader)
+
+// NewGRPCUnaryInterceptor creates a grpc.UnaryClientInterceptor that
+// transparently pays for and attaches LSATs to outgoing unary calls,
+// keying stored tokens by the called gRPC service's full name.
+func NewGRPCUnaryInterceptor(cfg *Config) grpc.UnaryClientInterceptor {
+	i := newInterceptor(cfg)
+
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption) error {
+
+		target := serviceName(method)
+
+		entry, err := i.tokenFor(ctx, target)
+		if err != nil {
+			return err
+		}
+
+		callCtx := ctx
+		if entry != nil {
+			callCtx = attachToken(ctx, entry)
+		}
+
+		var header metadata.MD
+		err = invoker(
+			callCtx, method, req, reply, cc,
+			append(opts, grpc.Header(&header))...,
+		)
+		if status.Code(err) != codes.Unauthenticated {
+			return err
+		}
+
+		challenge, cerr := challengeFromMetadata(header)
+		if cerr != nil {
+			return err
+		}
+
+		if entry != nil {
+			if ferr := i.markFailed(ctx, target, entry); ferr != nil {
+				return ferr
+			}
+		}
+
+		newEntry, perr := i.pay(ctx, target, challenge)
+		if perr != nil {
+			return perr
+		}
+
+		return invoker(
+			attachToken(ctx, newEntry), method, req, reply, cc,
+			opts...,
+		)
+	}
+}
+
+// NewGRPCStreamInterceptor creates a grpc.StreamClientInterceptor with the
+// same pay-and-retry behavior as NewGRPCUnaryInterceptor. A stream can't be
+// transparently resumed once the server has started rejecting messages on
+// it, so payment only happens before the stream is established.
+func NewGRPCStreamInterceptor(cfg *Config) grpc.StreamClientInterceptor {
+	i := newInterceptor(cfg)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc,
+		cc *grpc.ClientConn, method string, streamer grpc.Streamer,
+		opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		target := serviceName(method)
+
+		entry, err := i.tokenFor(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+
+		callCtx := ctx
+		if entry != nil {
+			callCtx = attachToken(ctx, entry)
+		}
+
+		var header metadata.MD
+		stream, err := streamer(
+			callCtx, desc, cc, method,
+			append(opts, grpc.Header(&header))...,
+		)
+		if status.Code(err) != codes.Unauthenticated {
+			return stream, err
+		}
+
+		challenge, cerr := challengeFromMetadata(header)
+		if cerr != nil {
+			return nil, err
+		}
+
+		if entry != nil {
+			if ferr := i.markFailed(ctx, target, entry); ferr != nil {
+				return nil, ferr
+			}
+		}
+
+		newEntry, perr := i.pay(ctx, target, challenge)
+		if perr != nil {
+			return nil, perr
+		}
+
+		return streamer(attachToken(ctx, newEntry), desc, cc, method, opts...)
+	}
+}
+
+// attachToken returns a context with entry's LSAT attached as outgoing
+// gRPC metadata.
+func attachToken(ctx context.Context, entry *TokenEntry) context.Context {
+	return metadata.AppendToOutgoingContext(
+		ctx,
+		MacaroonMetadataKey, hex.EncodeToString(entry.Macaroon),
+		PreimageMetadataKey, entry.Preimage.String(),
+	)
+}
+
+// challengeFromMetadata extracts a payment Challenge from the
+// WWW-Authenticate entry of header, as forwarded by Aperture's gRPC
+// gateway alongside an Unauthenticated status.
+func challengeFromMetadata(header metadata.MD) (*lsat.Challenge, error) {
+	values := header.Get(authHeaderMetadataKey)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no LSAT challenge in response metadata")
+	}
+
+	return lsat.ParseChallenge(values[0])
+}
+
+// serviceName returns the gRPC service name a fully qualified method
+// ("/pkg.Service/Method") belongs to.
+func serviceName(method string) string {
+	method = strings.TrimPrefix(method, "/")
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		return method[:i]
+	}
+	return method
+}
@@ -0,0 +1,256 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testMethod = "/aperture.test.Service/Method"
+
+// setHeader scans opts for the grpc.Header call option the interceptor
+// attaches to its invoker/streamer calls and fills it with md, mimicking
+// what a real grpc.ClientConn would do with the headers a server sent back.
+func setHeader(opts []grpc.CallOption, md metadata.MD) {
+	for _, opt := range opts {
+		if h, ok := opt.(grpc.HeaderCallOption); ok {
+			*h.HeaderAddr = md
+		}
+	}
+}
+
+// unauthenticatedWithChallenge builds the metadata and error a gRPC gateway
+// forwards when it rejects a call for lacking (or presenting an invalid)
+// LSAT: an Unauthenticated status plus the challenge under the forwarded
+// WWW-Authenticate metadata key.
+func unauthenticatedWithChallenge(challenge string) (metadata.MD, error) {
+	md := metadata.Pairs(authHeaderMetadataKey, challenge)
+	return md, status.Error(codes.Unauthenticated, "payment required")
+}
+
+// TestGRPCUnaryInterceptorPaysAndRetries confirms that the unary interceptor,
+// on a first Unauthenticated response, pays the presented challenge and
+// retries the call with the resulting LSAT attached, succeeding without the
+// caller ever seeing the original error.
+func TestGRPCUnaryInterceptorPaysAndRetries(t *testing.T) {
+	payer := &fakePayer{amount: 1000}
+	store := NewMemoryStore()
+	challenge := newChallengeHeader(t, "lnbc1...testinvoice")
+
+	// invoker simulates a backend that accepts any call already carrying
+	// an LSAT and challenges any call that isn't, the same way a real
+	// Aperture-fronted service would.
+	var calls int
+	invoker := func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+
+		calls++
+		if _, ok := metadata.FromOutgoingContext(ctx); ok {
+			return nil
+		}
+
+		md, err := unauthenticatedWithChallenge(challenge)
+		setHeader(opts, md)
+		return err
+	}
+
+	interceptor := NewGRPCUnaryInterceptor(&Config{
+		Store:   store,
+		Payer:   payer,
+		MaxCost: 10_000,
+	})
+
+	err := interceptor(
+		context.Background(), testMethod, nil, nil,
+		(*grpc.ClientConn)(nil), invoker,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 invocations (challenge + retry), got %d", calls)
+	}
+	if payer.paidCnt != 1 {
+		t.Fatalf("expected exactly 1 payment, got %d", payer.paidCnt)
+	}
+
+	// A second call should reuse the stored token without paying again.
+	calls = 0
+	err = interceptor(
+		context.Background(), testMethod, nil, nil,
+		(*grpc.ClientConn)(nil), invoker,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error on reuse: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the stored token to avoid a second challenge "+
+			"round trip, got %d invocations", calls)
+	}
+	if payer.paidCnt != 1 {
+		t.Fatalf("expected no additional payment, got %d payments",
+			payer.paidCnt)
+	}
+}
+
+// TestGRPCUnaryInterceptorStoredTokenRejectedMintsFresh confirms that when a
+// previously stored token is rejected, the interceptor marks it failed and
+// mints a fresh one rather than reusing it again.
+func TestGRPCUnaryInterceptorStoredTokenRejectedMintsFresh(t *testing.T) {
+	ctx := context.Background()
+	payer := &fakePayer{amount: 1000}
+	store := NewMemoryStore()
+
+	target := serviceName(testMethod)
+	staleEntry := &TokenEntry{Macaroon: []byte("stale-macaroon"), State: TokenStateActive}
+	if err := store.PutToken(ctx, target, staleEntry); err != nil {
+		t.Fatalf("unable to seed stored token: %v", err)
+	}
+
+	challenge := newChallengeHeader(t, "lnbc1...freshinvoice")
+	var calls int
+	invoker := func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+
+		calls++
+		if calls == 1 {
+			md, err := unauthenticatedWithChallenge(challenge)
+			setHeader(opts, md)
+			return err
+		}
+		return nil
+	}
+
+	interceptor := NewGRPCUnaryInterceptor(&Config{
+		Store:   store,
+		Payer:   payer,
+		MaxCost: 10_000,
+	})
+
+	if err := interceptor(
+		ctx, testMethod, nil, nil, (*grpc.ClientConn)(nil), invoker,
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payer.paidCnt != 1 {
+		t.Fatalf("expected exactly 1 payment, got %d", payer.paidCnt)
+	}
+
+	stored, err := store.CurrentToken(ctx, target)
+	if err != nil {
+		t.Fatalf("unable to fetch stored token: %v", err)
+	}
+	if string(stored.Macaroon) == string(staleEntry.Macaroon) {
+		t.Fatalf("expected the stale token to be replaced by a fresh one")
+	}
+	if stored.State != TokenStateActive {
+		t.Fatalf("expected the fresh token to be active, got state %v",
+			stored.State)
+	}
+}
+
+// TestGRPCUnaryInterceptorNoRetryLoop confirms that the interceptor never
+// retries more than once: if the retry itself is also rejected, that error
+// is returned to the caller instead of paying and retrying indefinitely.
+func TestGRPCUnaryInterceptorNoRetryLoop(t *testing.T) {
+	payer := &fakePayer{amount: 1000}
+	store := NewMemoryStore()
+	challenge := newChallengeHeader(t, "lnbc1...testinvoice")
+
+	var calls int
+	invoker := func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+
+		calls++
+		md, err := unauthenticatedWithChallenge(challenge)
+		setHeader(opts, md)
+		return err
+	}
+
+	interceptor := NewGRPCUnaryInterceptor(&Config{
+		Store:   store,
+		Payer:   payer,
+		MaxCost: 10_000,
+	})
+
+	err := interceptor(
+		context.Background(), testMethod, nil, nil,
+		(*grpc.ClientConn)(nil), invoker,
+	)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected the still-rejected retry's error to surface, "+
+			"got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected exactly 2 invocations (no further retries), "+
+			"got %d", calls)
+	}
+	if payer.paidCnt != 1 {
+		t.Fatalf("expected exactly 1 payment attempt, got %d", payer.paidCnt)
+	}
+}
+
+// fakeClientStream is a no-op grpc.ClientStream used to stand in for the
+// stream a real grpc.Streamer would return.
+type fakeClientStream struct{}
+
+func (fakeClientStream) Header() (metadata.MD, error) { return nil, nil }
+func (fakeClientStream) Trailer() metadata.MD         { return nil }
+func (fakeClientStream) CloseSend() error             { return nil }
+func (fakeClientStream) Context() context.Context     { return context.Background() }
+func (fakeClientStream) SendMsg(interface{}) error    { return nil }
+func (fakeClientStream) RecvMsg(interface{}) error    { return nil }
+
+// TestGRPCStreamInterceptorPaysAndRetries confirms that the stream
+// interceptor pays a presented challenge before establishing the stream,
+// mirroring the unary interceptor's pay-and-retry behavior.
+func TestGRPCStreamInterceptorPaysAndRetries(t *testing.T) {
+	payer := &fakePayer{amount: 1000}
+	store := NewMemoryStore()
+	challenge := newChallengeHeader(t, "lnbc1...streaminvoice")
+
+	var calls int
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc,
+		cc *grpc.ClientConn, method string,
+		opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		calls++
+		if calls == 1 {
+			md, err := unauthenticatedWithChallenge(challenge)
+			setHeader(opts, md)
+			return nil, err
+		}
+
+		if _, ok := metadata.FromOutgoingContext(ctx); !ok {
+			t.Fatalf("expected outgoing metadata on retry")
+		}
+		return fakeClientStream{}, nil
+	}
+
+	interceptor := NewGRPCStreamInterceptor(&Config{
+		Store:   store,
+		Payer:   payer,
+		MaxCost: 10_000,
+	})
+
+	stream, err := interceptor(
+		context.Background(), &grpc.StreamDesc{}, (*grpc.ClientConn)(nil),
+		testMethod, streamer,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stream == nil {
+		t.Fatal("expected a non-nil stream on success")
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 invocations (challenge + retry), got %d", calls)
+	}
+	if payer.paidCnt != 1 {
+		t.Fatalf("expected exactly 1 payment, got %d", payer.paidCnt)
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/lightninglabs/aperture/lsat"
+)
+
+// AuthorizationHeader is the header a paid LSAT is presented back to the
+// server in.
+const AuthorizationHeader = "Authorization"
+
+// RoundTripper is an http.RoundTripper that transparently pays for and
+// attaches LSATs to outgoing requests, keying stored tokens by the
+// request's host.
+type RoundTripper struct {
+	*interceptor
+
+	// Base is the underlying RoundTripper used to actually perform
+	// requests. http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+}
+
+// NewRoundTripper creates a RoundTripper backed by cfg, wrapping base (or
+// http.DefaultTransport if base is nil).
+func NewRoundTripper(cfg *Config, base http.RoundTripper) *RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RoundTripper{interceptor: newInterceptor(cfg), Base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	target := req.URL.Host
+	ctx := req.Context()
+
+	entry, err := r.tokenFor(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := req
+	if entry != nil {
+		attempt = withToken(req, entry)
+	}
+
+	resp, err := r.Base.RoundTrip(attempt)
+	if err != nil || resp.StatusCode != http.StatusPaymentRequired {
+		return resp, err
+	}
+	_ = resp.Body.Close()
+
+	challenge, err := lsat.ParseChallenge(resp.Header.Get(lsat.AuthHeader))
+	if err != nil {
+		return nil, err
+	}
+
+	if entry != nil {
+		if err := r.markFailed(ctx, target, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	newEntry, err := r.pay(ctx, target, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Base.RoundTrip(withToken(req, newEntry))
+}
+
+// withToken clones req with entry's LSAT attached as its Authorization
+// header, rewinding the request body if the original supports it so the
+// same request can be safely retried.
+func withToken(req *http.Request, entry *TokenEntry) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set(AuthorizationHeader, entry.authValue())
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			clone.Body = body
+		}
+	}
+
+	return clone
+}
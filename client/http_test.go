@@ -0,0 +1,179 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightningnetwork/lnd/lntypes"
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+// fakePayer is a stub InvoicePayer that settles every invoice it's handed
+// for a fixed amount, without ever talking to a real Lightning node.
+type fakePayer struct {
+	mu      sync.Mutex
+	amount  int64
+	paidCnt int
+}
+
+func (p *fakePayer) DecodePaymentRequest(context.Context,
+	string) (*PaymentRequest, error) {
+
+	return &PaymentRequest{AmountMsat: p.amount}, nil
+}
+
+func (p *fakePayer) PayInvoice(_ context.Context, _ string,
+	_ int64) (lntypes.Preimage, int64, error) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paidCnt++
+
+	var preimage lntypes.Preimage
+	if _, err := rand.Read(preimage[:]); err != nil {
+		return preimage, 0, err
+	}
+
+	return preimage, p.amount, nil
+}
+
+// newChallengeHeader builds a WWW-Authenticate header value presenting a
+// freshly minted, unauthenticated macaroon alongside invoice.
+func newChallengeHeader(t *testing.T, invoice string) string {
+	t.Helper()
+
+	tokenID, err := lsat.NewTokenID()
+	if err != nil {
+		t.Fatalf("unable to generate token id: %v", err)
+	}
+
+	id := &lsat.Identifier{Version: lsat.LatestVersion, TokenID: tokenID}
+	var idBuf bytes.Buffer
+	if err := lsat.EncodeIdentifier(&idBuf, id); err != nil {
+		t.Fatalf("unable to encode identifier: %v", err)
+	}
+
+	mac, err := macaroon.New(
+		make([]byte, lsat.SecretSize), idBuf.Bytes(), "aperture",
+		macaroon.LatestVersion,
+	)
+	if err != nil {
+		t.Fatalf("unable to create macaroon: %v", err)
+	}
+
+	macBytes, err := mac.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unable to serialize macaroon: %v", err)
+	}
+
+	return fmt.Sprintf(
+		`LSAT macaroon="%s", invoice="%s"`,
+		hex.EncodeToString(macBytes), invoice,
+	)
+}
+
+// TestRoundTripperPaysAndRetries confirms that the RoundTripper, on a first
+// 402 response, pays the presented challenge and retries the request with
+// the resulting LSAT attached, succeeding without the caller ever seeing
+// the 402.
+func TestRoundTripperPaysAndRetries(t *testing.T) {
+	payer := &fakePayer{amount: 1000}
+	store := NewMemoryStore()
+
+	var challengeHeader string
+	var requests int
+	backend := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+
+			auth := r.Header.Get(AuthorizationHeader)
+			if auth == "" {
+				w.Header().Set(lsat.AuthHeader, challengeHeader)
+				w.WriteHeader(http.StatusPaymentRequired)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		},
+	))
+	defer backend.Close()
+	challengeHeader = newChallengeHeader(t, "lnbc1...testinvoice")
+
+	rt := NewRoundTripper(&Config{
+		Store:   store,
+		Payer:   payer,
+		MaxCost: 10_000,
+	}, nil)
+	client := &http.Client{Transport: rt}
+
+	resp, err := client.Get(backend.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", resp.StatusCode)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests (challenge + retry), got %d",
+			requests)
+	}
+	if payer.paidCnt != 1 {
+		t.Fatalf("expected exactly 1 payment, got %d", payer.paidCnt)
+	}
+
+	// A second call should reuse the stored token without paying again.
+	resp2, err := client.Get(backend.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", resp2.StatusCode)
+	}
+	if payer.paidCnt != 1 {
+		t.Fatalf("expected no additional payment, got %d payments",
+			payer.paidCnt)
+	}
+}
+
+// TestRoundTripperRejectsOverMaxCost confirms that a challenge whose
+// invoice exceeds Config.MaxCost is rejected without ever calling PayInvoice.
+func TestRoundTripperRejectsOverMaxCost(t *testing.T) {
+	payer := &fakePayer{amount: 50_000}
+	store := NewMemoryStore()
+
+	challengeHeader := newChallengeHeader(t, "lnbc1...expensiveinvoice")
+	backend := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(lsat.AuthHeader, challengeHeader)
+			w.WriteHeader(http.StatusPaymentRequired)
+		},
+	))
+	defer backend.Close()
+
+	rt := NewRoundTripper(&Config{
+		Store:   store,
+		Payer:   payer,
+		MaxCost: 10_000,
+	}, nil)
+	client := &http.Client{Transport: rt}
+
+	_, err := client.Get(backend.URL)
+	if err == nil {
+		t.Fatal("expected error for invoice exceeding max cost")
+	}
+	if payer.paidCnt != 0 {
+		t.Fatalf("expected no payment attempt, got %d", payer.paidCnt)
+	}
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightningnetwork/lnd/lntypes"
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+// PaymentRequest describes the invoice a Challenge asks the client to pay,
+// as decoded by an InvoicePayer.
+type PaymentRequest struct {
+	// AmountMsat is the amount, in millisatoshis, the invoice requests.
+	AmountMsat int64
+}
+
+// InvoicePayer is the subset of a Lightning node client the interceptor
+// needs to settle a payment challenge. It's intentionally narrow, the same
+// way Challenger and ServiceLimiter scope down the mint's own dependencies
+// -- most callers will satisfy it with an lndclient.LightningClient.
+type InvoicePayer interface {
+	// DecodePaymentRequest decodes invoice without paying it, so the
+	// interceptor can enforce Config.MaxCost and invoke Config.Confirm
+	// before spending anything.
+	DecodePaymentRequest(ctx context.Context, invoice string) (*PaymentRequest, error)
+
+	// PayInvoice pays invoice, refusing to spend more than maxFeeMsat in
+	// routing fees on top of its amount, and returns the preimage
+	// proving settlement along with the total amount paid (amount plus
+	// fees), in millisatoshis.
+	PayInvoice(ctx context.Context, invoice string,
+		maxFeeMsat int64) (lntypes.Preimage, int64, error)
+}
+
+// ConfirmFunc is called before paying a challenge that's within MaxCost, so
+// the caller can approve or reject the spend, e.g. by prompting a user. A
+// nil ConfirmFunc approves every challenge within MaxCost.
+type ConfirmFunc func(invoice string, amountMsat int64) bool
+
+// Config configures the pay-and-retry behavior shared by the gRPC
+// interceptors and the HTTP RoundTripper.
+type Config struct {
+	// Store persists tokens across calls (and, if backed by FileStore or
+	// BboltStore, across process restarts).
+	Store Store
+
+	// Payer settles the Lightning invoice backing a challenge.
+	Payer InvoicePayer
+
+	// MaxCost is the highest amount, in millisatoshis, the client is
+	// willing to pay for a single LSAT. Challenges above this are
+	// rejected without invoking Confirm.
+	MaxCost int64
+
+	// Confirm, if set, is called before paying a challenge within
+	// MaxCost.
+	Confirm ConfirmFunc
+}
+
+// interceptor holds the pay-and-retry logic shared by every transport: look
+// up a stored token for a target, pay for a fresh one if there is none (or
+// the stored one has failed), and mark a token failed once the server has
+// rejected it so it's never retried.
+type interceptor struct {
+	cfg *Config
+}
+
+func newInterceptor(cfg *Config) *interceptor {
+	return &interceptor{cfg: cfg}
+}
+
+// tokenFor returns the currently usable token for target, or nil if there
+// is none (including if the stored one has already failed).
+func (i *interceptor) tokenFor(ctx context.Context,
+	target string) (*TokenEntry, error) {
+
+	entry, err := i.cfg.Store.CurrentToken(ctx, target)
+	if err == ErrNoToken {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if entry.State == TokenStateFailed {
+		return nil, nil
+	}
+
+	return entry, nil
+}
+
+// markFailed records that the token presented for target was rejected by
+// the server, so it's never presented again. A fresh call to pay is needed
+// to mint a new one.
+func (i *interceptor) markFailed(ctx context.Context, target string,
+	entry *TokenEntry) error {
+
+	failed := *entry
+	failed.State = TokenStateFailed
+	return i.cfg.Store.PutToken(ctx, target, &failed)
+}
+
+// pay settles challenge's invoice, subject to the configured cost limit and
+// confirmation callback, and stores the resulting token for target.
+func (i *interceptor) pay(ctx context.Context, target string,
+	challenge *lsat.Challenge) (*TokenEntry, error) {
+
+	macBytes, err := hex.DecodeString(challenge.Macaroon)
+	if err != nil {
+		return nil, fmt.Errorf("invalid macaroon in challenge: %v", err)
+	}
+
+	var mac macaroon.Macaroon
+	if err := mac.UnmarshalBinary(macBytes); err != nil {
+		return nil, fmt.Errorf("invalid macaroon in challenge: %v", err)
+	}
+
+	id, err := lsat.DecodeIdentifier(bytes.NewReader(mac.Id()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid token identifier in "+
+			"challenge: %v", err)
+	}
+
+	req, err := i.cfg.Payer.DecodePaymentRequest(ctx, challenge.Invoice)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode invoice: %v", err)
+	}
+
+	if req.AmountMsat > i.cfg.MaxCost {
+		return nil, fmt.Errorf("invoice amount %d msat exceeds max "+
+			"cost %d msat", req.AmountMsat, i.cfg.MaxCost)
+	}
+
+	if i.cfg.Confirm != nil && !i.cfg.Confirm(challenge.Invoice, req.AmountMsat) {
+		return nil, fmt.Errorf("payment of %d msat declined",
+			req.AmountMsat)
+	}
+
+	preimage, amountPaid, err := i.cfg.Payer.PayInvoice(
+		ctx, challenge.Invoice, i.cfg.MaxCost-req.AmountMsat,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to pay invoice: %v", err)
+	}
+
+	entry := &TokenEntry{
+		ID:         id.TokenID,
+		Macaroon:   macBytes,
+		Preimage:   preimage,
+		Invoice:    challenge.Invoice,
+		AmountPaid: amountPaid,
+		State:      TokenStateActive,
+	}
+	if err := i.cfg.Store.PutToken(ctx, target, entry); err != nil {
+		return nil, fmt.Errorf("unable to persist token: %v", err)
+	}
+
+	return entry, nil
+}
+
+// authValue formats entry as the value of the header (or metadata entry) an
+// LSAT is presented back to the server in.
+func (e *TokenEntry) authValue() string {
+	return lsat.AuthorizationValue(hex.EncodeToString(e.Macaroon), e.Preimage)
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore is a Store that keeps all tokens purely in memory. It's only
+// suitable for testing and short-lived processes, since tokens do not
+// survive a restart.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tokens map[string]*TokenEntry
+}
+
+// NewMemoryStore creates a new, empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tokens: make(map[string]*TokenEntry),
+	}
+}
+
+// CurrentToken returns the token currently stored for target.
+func (s *MemoryStore) CurrentToken(_ context.Context,
+	target string) (*TokenEntry, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.tokens[target]
+	if !ok {
+		return nil, ErrNoToken
+	}
+
+	return entry, nil
+}
+
+// PutToken stores entry as the current token for target.
+func (s *MemoryStore) PutToken(_ context.Context, target string,
+	entry *TokenEntry) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[target] = entry
+	return nil
+}
@@ -0,0 +1,68 @@
+// Package client provides reusable building blocks for programs that call
+// LSAT-gated services: a pay-and-retry state machine shared by a
+// grpc.UnaryClientInterceptor, a grpc.StreamClientInterceptor, and an
+// http.RoundTripper, backed by a pluggable Store so paid tokens survive
+// across calls (and, with a persistent Store, across restarts) instead of
+// being paid for again on every request.
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// TokenState tracks where in its lifecycle a locally stored token is.
+type TokenState uint8
+
+const (
+	// TokenStateActive means the token has been paid for and is
+	// currently usable.
+	TokenStateActive TokenState = iota
+
+	// TokenStateFailed means the target rejected the token (e.g. it was
+	// revoked or expired) and it must not be presented again.
+	TokenStateFailed
+)
+
+// TokenEntry is everything the client needs to remember about a single LSAT
+// in order to reuse it on subsequent calls without paying again.
+type TokenEntry struct {
+	// ID is the token's unique identifier, as minted by the server.
+	ID lsat.TokenID
+
+	// Macaroon is the serialized macaroon portion of the LSAT.
+	Macaroon []byte
+
+	// Preimage is the payment preimage proving the backing invoice was
+	// settled.
+	Preimage lntypes.Preimage
+
+	// Invoice is the BOLT11 invoice that was paid to mint the token.
+	Invoice string
+
+	// AmountPaid is the total amount, in millisatoshis, that was paid
+	// (including routing fees) to obtain the token.
+	AmountPaid int64
+
+	// State is the token's current lifecycle state.
+	State TokenState
+}
+
+// ErrNoToken is returned by a Store when no token is stored for a target.
+var ErrNoToken = errors.New("no token stored for target")
+
+// Store persists the tokens a client has obtained, keyed by the target they
+// were minted for (typically a service's host:port or gRPC service name),
+// so they can be reused across calls instead of paying again for every one.
+type Store interface {
+	// CurrentToken returns the token currently stored for target, or
+	// ErrNoToken if none exists.
+	CurrentToken(ctx context.Context, target string) (*TokenEntry, error)
+
+	// PutToken stores entry as the current token for target, replacing
+	// whatever was stored before.
+	PutToken(ctx context.Context, target string, entry *TokenEntry) error
+}
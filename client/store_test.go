@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lightninglabs/aperture/lsat"
+)
+
+// testStoreRoundTrip runs a battery of behavioral checks against store that
+// every persistent Store implementation, regardless of backend, must
+// satisfy.
+func testStoreRoundTrip(t *testing.T, store Store) {
+	t.Helper()
+
+	ctx := context.Background()
+	const target = "loop.lightning.engineering:11010"
+
+	if _, err := store.CurrentToken(ctx, target); err != ErrNoToken {
+		t.Fatalf("expected ErrNoToken, got %v", err)
+	}
+
+	entry := &TokenEntry{
+		ID:         lsat.TokenID{1, 2, 3},
+		Macaroon:   []byte("test-macaroon"),
+		Invoice:    "lnbc1...testinvoice",
+		AmountPaid: 1000,
+		State:      TokenStateActive,
+	}
+	if err := store.PutToken(ctx, target, entry); err != nil {
+		t.Fatalf("unable to store token: %v", err)
+	}
+
+	got, err := store.CurrentToken(ctx, target)
+	if err != nil {
+		t.Fatalf("unable to fetch stored token: %v", err)
+	}
+	if string(got.Macaroon) != string(entry.Macaroon) ||
+		got.Invoice != entry.Invoice ||
+		got.AmountPaid != entry.AmountPaid ||
+		got.State != entry.State {
+
+		t.Fatalf("expected stored token %+v, got %+v", entry, got)
+	}
+
+	// Storing a new entry for the same target should replace it outright.
+	failed := *entry
+	failed.State = TokenStateFailed
+	if err := store.PutToken(ctx, target, &failed); err != nil {
+		t.Fatalf("unable to overwrite stored token: %v", err)
+	}
+	got, err = store.CurrentToken(ctx, target)
+	if err != nil {
+		t.Fatalf("unable to fetch overwritten token: %v", err)
+	}
+	if got.State != TokenStateFailed {
+		t.Fatalf("expected overwritten token to be failed, got state %v",
+			got.State)
+	}
+}
+
+// TestFileStoreRoundTrip confirms that a FileStore persists tokens to disk
+// and reads them back correctly, including across a fresh FileStore opened
+// against the same directory (simulating a process restart).
+func TestFileStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("unable to create file store: %v", err)
+	}
+	testStoreRoundTrip(t, store)
+
+	// A fresh FileStore opened against the same directory should see the
+	// tokens written by the first one.
+	reopened, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("unable to reopen file store: %v", err)
+	}
+	entry, err := reopened.CurrentToken(
+		context.Background(), "loop.lightning.engineering:11010",
+	)
+	if err != nil {
+		t.Fatalf("unable to fetch token from reopened store: %v", err)
+	}
+	if entry.State != TokenStateFailed {
+		t.Fatalf("expected token to survive reopening the store, got %+v",
+			entry)
+	}
+}
+
+// TestBboltStoreRoundTrip confirms that a BboltStore persists tokens across
+// a fresh instance opened against the same database file, simulating a
+// process restart.
+func TestBboltStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewBboltStore(dir)
+	if err != nil {
+		t.Fatalf("unable to create bbolt store: %v", err)
+	}
+	testStoreRoundTrip(t, store)
+	if err := store.Close(); err != nil {
+		t.Fatalf("unable to close bbolt store: %v", err)
+	}
+
+	reopened, err := NewBboltStore(dir)
+	if err != nil {
+		t.Fatalf("unable to reopen bbolt store: %v", err)
+	}
+	defer reopened.Close()
+
+	entry, err := reopened.CurrentToken(
+		context.Background(), "loop.lightning.engineering:11010",
+	)
+	if err != nil {
+		t.Fatalf("unable to fetch token from reopened store: %v", err)
+	}
+	if entry.State != TokenStateFailed {
+		t.Fatalf("expected token to survive reopening the store, got %+v",
+			entry)
+	}
+}
@@ -0,0 +1,74 @@
+// Package discharge implements a third-party discharge authority: a
+// service that can mint discharge macaroons satisfying third-party caveats
+// attached by an Aperture mint (or any other macaroon-based service that
+// points at it), gated behind a pluggable Policy.
+package discharge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lightninglabs/aperture/mint"
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+// Policy decides whether a given caveat condition should be discharged for
+// the requester described by req. Implementations encode whatever trust
+// relationship the discharge authority offers, e.g. "the user has proven
+// KYC" or "the user's Nostr pubkey matches".
+type Policy interface {
+	// Approve returns nil if condition should be discharged, or an
+	// error explaining why it was denied otherwise.
+	Approve(ctx context.Context, condition string, req *Request) error
+}
+
+// Request carries the caller-supplied context a Policy needs to make its
+// decision, beyond the caveat condition itself.
+type Request struct {
+	// RemoteAddr is the address the discharge request originated from.
+	RemoteAddr string
+
+	// Credential is an opaque, policy-specific proof supplied by the
+	// caller, e.g. a signed attestation or session token.
+	Credential string
+}
+
+// Authority mints discharge macaroons for third-party caveats created by a
+// mint.ThirdPartyMinter pointed at it.
+type Authority struct {
+	// PrivateKey is this authority's NaCl box private key, used to
+	// recover the root key and condition sealed into a caveat
+	// identifier by the primary mint.
+	PrivateKey *[32]byte
+
+	// Policy decides whether a given condition should be discharged.
+	Policy Policy
+
+	// Location is included as the discharge macaroon's own location.
+	Location string
+}
+
+// Discharge attempts to produce a discharge macaroon for the third-party
+// caveat identified by caveatID, as created by
+// mint.ThirdPartyMinter.AddThirdPartyCaveat.
+func (a *Authority) Discharge(ctx context.Context, caveatID []byte,
+	req *Request) (*macaroon.Macaroon, error) {
+
+	rootKey, condition, err := mint.OpenThirdPartyCaveatID(a.PrivateKey, caveatID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open caveat id: %v", err)
+	}
+
+	if err := a.Policy.Approve(ctx, condition, req); err != nil {
+		return nil, fmt.Errorf("discharge denied: %v", err)
+	}
+
+	dischargeMac, err := macaroon.New(
+		rootKey, caveatID, a.Location, macaroon.LatestVersion,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to mint discharge macaroon: %v", err)
+	}
+
+	return dischargeMac, nil
+}
@@ -0,0 +1,84 @@
+package discharge
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+)
+
+// Server exposes an Authority over HTTP.
+type Server struct {
+	authority *Authority
+}
+
+// NewServer creates a Server backed by authority.
+func NewServer(authority *Authority) *Server {
+	return &Server{authority: authority}
+}
+
+// dischargeRequest is the body accepted by POST /v1/discharge.
+type dischargeRequest struct {
+	// CaveatID is the base64-encoded third-party caveat identifier, as
+	// found on the macaroon the client is trying to get discharged.
+	CaveatID string `json:"caveat_id"`
+
+	// Credential is an opaque, policy-specific proof the caller supplies
+	// to satisfy the authority's Policy.
+	Credential string `json:"credential,omitempty"`
+}
+
+// dischargeResponse is the body returned by a successful discharge.
+type dischargeResponse struct {
+	// Macaroon is the base64-encoded, serialized discharge macaroon. The
+	// caller must bind it to the primary macaroon (via
+	// discharge.Bind(primary.Signature())) before presenting the pair
+	// together.
+	Macaroon string `json:"macaroon"`
+}
+
+// Handler returns the HTTP handler implementing POST /v1/discharge.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/discharge", s.handleDischarge)
+	return mux
+}
+
+func (s *Server) handleDischarge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dischargeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	caveatID, err := base64.StdEncoding.DecodeString(req.CaveatID)
+	if err != nil {
+		http.Error(w, "invalid caveat_id", http.StatusBadRequest)
+		return
+	}
+
+	dischargeMac, err := s.authority.Discharge(r.Context(), caveatID, &Request{
+		RemoteAddr: r.RemoteAddr,
+		Credential: req.Credential,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	macBytes, err := dischargeMac.MarshalBinary()
+	if err != nil {
+		http.Error(w, "unable to serialize discharge macaroon",
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(dischargeResponse{
+		Macaroon: base64.StdEncoding.EncodeToString(macBytes),
+	})
+}
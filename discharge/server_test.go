@@ -0,0 +1,205 @@
+package discharge
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightninglabs/aperture/mint"
+	"github.com/lightninglabs/aperture/store"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"golang.org/x/crypto/nacl/box"
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+type mockChallenger struct{}
+
+func (mockChallenger) NewChallenge(int64) (string, lntypes.Hash, error) {
+	return "lnbc1...mockinvoice", lntypes.Hash{1, 2, 3}, nil
+}
+
+type mockServiceLimiter struct{}
+
+func (mockServiceLimiter) ServiceCapabilities(context.Context, ...lsat.Service) ([]lsat.Caveat, error) {
+	return nil, nil
+}
+
+func (mockServiceLimiter) ServiceTimeouts(context.Context, ...lsat.Service) ([]lsat.Caveat, error) {
+	return nil, nil
+}
+
+// TestThirdPartyDischargeFlow mints an LSAT with a third-party caveat,
+// obtains a discharge macaroon for it from an Authority's HTTP endpoint,
+// and confirms that the bound pair verifies successfully.
+func TestThirdPartyDischargeFlow(t *testing.T) {
+	ctx := context.Background()
+
+	authorityPub, authorityPriv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate authority keypair: %v", err)
+	}
+
+	authority := &Authority{
+		PrivateKey: authorityPriv,
+		Policy:     AllowAllPolicy{},
+		Location:   "https://discharge.example",
+	}
+	httpServer := httptest.NewServer(NewServer(authority).Handler())
+	defer httpServer.Close()
+
+	m := mint.New(&mint.Config{
+		Secrets:        store.NewMemoryStore(),
+		Challenger:     mockChallenger{},
+		ServiceLimiter: mockServiceLimiter{},
+	})
+
+	testService := lsat.Service{Name: "loop", Tier: lsat.BaseTier}
+	mac, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT: %v", err)
+	}
+
+	minter := mint.NewThirdPartyMinter(
+		"https://discharge.example", authorityPub,
+	)
+	sharedSecret := []byte("shared-secret-for-this-caveat-1")
+	if err := minter.AddThirdPartyCaveat(mac, "human", sharedSecret); err != nil {
+		t.Fatalf("unable to add third party caveat: %v", err)
+	}
+
+	caveats := mac.Caveats()
+	thirdPartyCaveat := caveats[len(caveats)-1]
+
+	reqBody, err := json.Marshal(map[string]string{
+		"caveat_id": base64.StdEncoding.EncodeToString(thirdPartyCaveat.Id),
+	})
+	if err != nil {
+		t.Fatalf("unable to marshal request: %v", err)
+	}
+
+	resp, err := http.Post(
+		httpServer.URL+"/v1/discharge", "application/json",
+		bytes.NewReader(reqBody),
+	)
+	if err != nil {
+		t.Fatalf("unable to request discharge: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 OK from discharge, got %d", resp.StatusCode)
+	}
+
+	var dischargeResp dischargeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dischargeResp); err != nil {
+		t.Fatalf("unable to decode discharge response: %v", err)
+	}
+
+	dischargeMacBytes, err := base64.StdEncoding.DecodeString(dischargeResp.Macaroon)
+	if err != nil {
+		t.Fatalf("unable to decode discharge macaroon: %v", err)
+	}
+
+	var dischargeMac macaroon.Macaroon
+	if err := dischargeMac.UnmarshalBinary(dischargeMacBytes); err != nil {
+		t.Fatalf("unable to deserialize discharge macaroon: %v", err)
+	}
+
+	dischargeMac.Bind(mac.Signature())
+
+	params := &mint.VerificationParams{
+		Macaroon:      mac,
+		TargetService: testService.Name,
+		Discharges:    []*macaroon.Macaroon{&dischargeMac},
+	}
+	if err := m.VerifyLSAT(ctx, params); err != nil {
+		t.Fatalf("unable to verify LSAT with discharge: %v", err)
+	}
+}
+
+// denyAllPolicy rejects every discharge request, regardless of condition.
+type denyAllPolicy struct{}
+
+func (denyAllPolicy) Approve(context.Context, string, *Request) error {
+	return fmt.Errorf("credential not recognized")
+}
+
+// TestThirdPartyDischargeDenied confirms that a discharge authority whose
+// Policy rejects the request returns an HTTP error instead of a macaroon,
+// and that the LSAT remains unverifiable without it.
+func TestThirdPartyDischargeDenied(t *testing.T) {
+	ctx := context.Background()
+
+	authorityPub, authorityPriv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate authority keypair: %v", err)
+	}
+
+	authority := &Authority{
+		PrivateKey: authorityPriv,
+		Policy:     denyAllPolicy{},
+		Location:   "https://discharge.example",
+	}
+	httpServer := httptest.NewServer(NewServer(authority).Handler())
+	defer httpServer.Close()
+
+	m := mint.New(&mint.Config{
+		Secrets:        store.NewMemoryStore(),
+		Challenger:     mockChallenger{},
+		ServiceLimiter: mockServiceLimiter{},
+	})
+
+	testService := lsat.Service{Name: "loop", Tier: lsat.BaseTier}
+	mac, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT: %v", err)
+	}
+
+	minter := mint.NewThirdPartyMinter(
+		"https://discharge.example", authorityPub,
+	)
+	sharedSecret := []byte("shared-secret-for-this-caveat-2")
+	if err := minter.AddThirdPartyCaveat(mac, "human", sharedSecret); err != nil {
+		t.Fatalf("unable to add third party caveat: %v", err)
+	}
+
+	caveats := mac.Caveats()
+	thirdPartyCaveat := caveats[len(caveats)-1]
+
+	reqBody, err := json.Marshal(map[string]string{
+		"caveat_id": base64.StdEncoding.EncodeToString(thirdPartyCaveat.Id),
+	})
+	if err != nil {
+		t.Fatalf("unable to marshal request: %v", err)
+	}
+
+	resp, err := http.Post(
+		httpServer.URL+"/v1/discharge", "application/json",
+		bytes.NewReader(reqBody),
+	)
+	if err != nil {
+		t.Fatalf("unable to request discharge: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 Forbidden from discharge, got %d",
+			resp.StatusCode)
+	}
+
+	// Without a discharge, verification must fail.
+	params := &mint.VerificationParams{
+		Macaroon:      mac,
+		TargetService: testService.Name,
+	}
+	if err := m.VerifyLSAT(ctx, params); err == nil {
+		t.Fatal("expected verification to fail without a discharge")
+	}
+}
@@ -0,0 +1,71 @@
+package lsat
+
+import (
+	"fmt"
+	"time"
+)
+
+// Condition names of the built-in caveats understood by the mint's default
+// Satisfier registry.
+const (
+	// CondValidUntil restricts an LSAT to be usable only until a given
+	// unix timestamp.
+	CondValidUntil = "valid_until"
+
+	// CondIPRange restricts an LSAT to only be usable from a client IP
+	// within a given CIDR range.
+	CondIPRange = "ip_range"
+
+	// CondIPRangeNot restricts an LSAT to only be usable from a client
+	// IP outside of a given CIDR range.
+	CondIPRangeNot = "ip_range_not"
+
+	// CondCapabilities restricts an LSAT to only a set of fine-grained
+	// capabilities within a service.
+	CondCapabilities = "capabilities"
+
+	// CondRateLimit restricts an LSAT to a maximum number of uses within
+	// a rolling window.
+	CondRateLimit = "ratelimit"
+)
+
+// NewValidUntilCaveat creates a caveat that restricts an LSAT to being valid
+// only until expiry.
+func NewValidUntilCaveat(expiry time.Time) Caveat {
+	return Caveat{
+		Id: []byte(fmt.Sprintf("%s=%d", CondValidUntil, expiry.Unix())),
+	}
+}
+
+// NewIPRangeCaveat creates a caveat that restricts an LSAT to only be
+// presented from a client IP within cidr.
+func NewIPRangeCaveat(cidr string) Caveat {
+	return Caveat{Id: []byte(fmt.Sprintf("%s=%s", CondIPRange, cidr))}
+}
+
+// NewIPRangeNotCaveat creates a caveat that restricts an LSAT to only be
+// presented from a client IP outside of cidr.
+func NewIPRangeNotCaveat(cidr string) Caveat {
+	return Caveat{Id: []byte(fmt.Sprintf("%s=%s", CondIPRangeNot, cidr))}
+}
+
+// NewCapabilitiesCaveat creates a caveat that restricts an LSAT to only the
+// given fine-grained capabilities.
+func NewCapabilitiesCaveat(capabilities ...string) Caveat {
+	csv := ""
+	for i, capability := range capabilities {
+		if i > 0 {
+			csv += ","
+		}
+		csv += capability
+	}
+	return Caveat{Id: []byte(fmt.Sprintf("%s=%s", CondCapabilities, csv))}
+}
+
+// NewRateLimitCaveat creates a caveat that restricts an LSAT to at most n
+// uses within window.
+func NewRateLimitCaveat(n int, window time.Duration) Caveat {
+	return Caveat{
+		Id: []byte(fmt.Sprintf("%s=%d/%s", CondRateLimit, n, window)),
+	}
+}
@@ -0,0 +1,31 @@
+package lsat
+
+import (
+	"strings"
+
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+// Caveat is an alias for the underlying macaroon caveat type, re-exported so
+// callers don't need to import gopkg.in/macaroon.v2 directly when working
+// with LSATs.
+type Caveat = macaroon.Caveat
+
+// AddFirstPartyCaveats attaches the given caveats to mac as first-party
+// caveats, further restricting what the bearer is authorized to do.
+func AddFirstPartyCaveats(mac *macaroon.Macaroon, caveats ...Caveat) error {
+	for _, caveat := range caveats {
+		if err := mac.AddFirstPartyCaveat(caveat.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CaveatCondition returns the condition name of a caveat encoded as
+// "name=value", e.g. "valid_until" for "valid_until=1700000000". If the
+// caveat doesn't contain an "=", the whole identifier is returned.
+func CaveatCondition(id string) string {
+	parts := strings.SplitN(id, "=", 2)
+	return parts[0]
+}
@@ -0,0 +1,66 @@
+package lsat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// AuthHeader is the HTTP (and gRPC metadata) header name an LSAT payment
+// challenge is communicated in. A paid token is presented back separately,
+// see AuthorizationValue.
+const AuthHeader = "WWW-Authenticate"
+
+// Challenge is the payment challenge a server presents in a 402 response,
+// instructing the client to pay Invoice before it will be granted the LSAT
+// described by Macaroon.
+type Challenge struct {
+	// Macaroon is the hex-encoded, unauthenticated macaroon portion of
+	// the LSAT the client must pay for and then present back.
+	Macaroon string
+
+	// Invoice is the BOLT11 invoice that must be paid to activate
+	// Macaroon.
+	Invoice string
+}
+
+// ParseChallenge extracts a Challenge from a header of the form
+// `LSAT macaroon="<hex>", invoice="<bolt11>"`, as found in a server's
+// WWW-Authenticate response header.
+func ParseChallenge(header string) (*Challenge, error) {
+	const prefix = "LSAT "
+	if !strings.HasPrefix(header, prefix) {
+		return nil, fmt.Errorf("not an LSAT challenge: %q", header)
+	}
+
+	challenge := &Challenge{}
+	for _, kv := range strings.Split(strings.TrimPrefix(header, prefix), ",") {
+		parts := strings.SplitN(strings.TrimSpace(kv), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"`)
+
+		switch key {
+		case "macaroon":
+			challenge.Macaroon = value
+		case "invoice":
+			challenge.Invoice = value
+		}
+	}
+
+	if challenge.Macaroon == "" || challenge.Invoice == "" {
+		return nil, fmt.Errorf("incomplete LSAT challenge: %q", header)
+	}
+
+	return challenge, nil
+}
+
+// AuthorizationValue formats a paid LSAT as the value of an Authorization
+// header (or equivalent gRPC metadata entry) presented back to the server.
+func AuthorizationValue(macaroonHex string, preimage lntypes.Preimage) string {
+	return fmt.Sprintf("LSAT %s:%s", macaroonHex, preimage.String())
+}
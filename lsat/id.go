@@ -0,0 +1,128 @@
+package lsat
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// Version is the version of an LSAT identifier. It allows the format of the
+// identifier to change over time while remaining backwards compatible with
+// tokens that were minted under an older version.
+type Version uint16
+
+const (
+	// Version0 is the initial version of the LSAT identifier.
+	Version0 Version = 0
+
+	// Version1 extends Version0 with a KeyID field, allowing the mint to
+	// rotate the long-lived key used to derive LSAT secrets without
+	// invalidating tokens minted under an older key. Identifiers
+	// encoded under Version0 are still decoded correctly, with KeyID
+	// defaulting to 0.
+	Version1 Version = 1
+
+	// LatestVersion is the identifier version used by new tokens minted
+	// by this version of the mint.
+	LatestVersion = Version1
+)
+
+// TokenIDSize is the size in bytes of a TokenID.
+const TokenIDSize = 32
+
+// TokenID is a randomly generated, unique identifier for a single LSAT.
+type TokenID [TokenIDSize]byte
+
+// NewTokenID generates a new cryptographically random token ID.
+func NewTokenID() (TokenID, error) {
+	var id TokenID
+	if _, err := rand.Read(id[:]); err != nil {
+		return id, fmt.Errorf("unable to generate token id: %v", err)
+	}
+	return id, nil
+}
+
+// MakeIDFromString parses a hex-encoded string into a TokenID.
+func MakeIDFromString(s string) (TokenID, error) {
+	var id TokenID
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return id, fmt.Errorf("unable to decode token id: %v", err)
+	}
+	if len(b) != TokenIDSize {
+		return id, fmt.Errorf("invalid token id length: got %d, "+
+			"want %d", len(b), TokenIDSize)
+	}
+
+	copy(id[:], b)
+	return id, nil
+}
+
+// Identifier is the information encoded in the identifier field of an LSAT's
+// macaroon. It links the macaroon back to the payment that was made in order
+// to obtain it.
+type Identifier struct {
+	// Version is the version of the identifier, allowing the mint to
+	// evolve the format of identifiers while still supporting tokens
+	// minted under an older format.
+	Version Version
+
+	// PaymentHash is the payment hash of the invoice that was paid to
+	// mint this LSAT.
+	PaymentHash lntypes.Hash
+
+	// TokenID uniquely identifies this particular LSAT.
+	TokenID TokenID
+
+	// KeyID identifies which version of the mint's long-lived
+	// pseudo-randomness key was active when this LSAT was minted, so
+	// that key rotation doesn't invalidate outstanding tokens. Only
+	// populated (and encoded) from Version1 onwards; Version0
+	// identifiers are implicitly key version 0.
+	KeyID uint32
+}
+
+// EncodeIdentifier serializes the identifier into its on-the-wire format,
+// which is used as the macaroon's identifier field.
+func EncodeIdentifier(w io.Writer, id *Identifier) error {
+	if err := binary.Write(w, binary.BigEndian, id.Version); err != nil {
+		return err
+	}
+	if _, err := w.Write(id.PaymentHash[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(id.TokenID[:]); err != nil {
+		return err
+	}
+	if id.Version >= Version1 {
+		if err := binary.Write(w, binary.BigEndian, id.KeyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeIdentifier deserializes an identifier from its on-the-wire format.
+func DecodeIdentifier(r io.Reader) (*Identifier, error) {
+	var id Identifier
+	if err := binary.Read(r, binary.BigEndian, &id.Version); err != nil {
+		return nil, fmt.Errorf("unable to read version: %v", err)
+	}
+	if _, err := io.ReadFull(r, id.PaymentHash[:]); err != nil {
+		return nil, fmt.Errorf("unable to read payment hash: %v", err)
+	}
+	if _, err := io.ReadFull(r, id.TokenID[:]); err != nil {
+		return nil, fmt.Errorf("unable to read token id: %v", err)
+	}
+	if id.Version >= Version1 {
+		if err := binary.Read(r, binary.BigEndian, &id.KeyID); err != nil {
+			return nil, fmt.Errorf("unable to read key id: %v", err)
+		}
+	}
+	return &id, nil
+}
@@ -0,0 +1,52 @@
+package lsat
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// SatisfyParams carries the request-scoped facts a Satisfier needs in order
+// to decide whether its caveat is satisfied by the current request. Not
+// every field is relevant to every satisfier.
+type SatisfyParams struct {
+	// Ctx is the context of the verification request, for satisfiers
+	// that need to consult external state (e.g. a rate limit store).
+	Ctx context.Context
+
+	// Now is the time the verification is taking place.
+	Now time.Time
+
+	// ClientIP is the IP address of the client presenting the LSAT, fed
+	// in by the proxy from the request context.
+	ClientIP net.IP
+
+	// Capability is the fine-grained capability the client is attempting
+	// to exercise, if applicable to the request being authorized. Leaving
+	// it empty does not grant unrestricted access: an LSAT carrying a
+	// capabilities caveat fails verification if no Capability is
+	// supplied, rather than silently bypassing the caveat.
+	Capability string
+
+	// TokenID uniquely identifies the LSAT being verified, decoded from
+	// its identifier. Satisfiers that need to track per-token state (e.g.
+	// a rate limit counter) should fold it into whatever key they use, so
+	// that two distinct tokens carrying an identical caveat don't share
+	// state.
+	TokenID TokenID
+}
+
+// Satisfier evaluates whether a single first-party caveat condition is met
+// for the current request. The mint maintains a registry of satisfiers
+// keyed by condition name (e.g. "valid_until"), so that arbitrary caveat
+// types beyond the built-in "services" one can be enforced at verification
+// time.
+type Satisfier interface {
+	// Name is the caveat condition name this satisfier handles.
+	Name() string
+
+	// Satisfy decides whether cav is satisfied given params. A non-nil
+	// error means the caveat is not satisfied and the LSAT must be
+	// rejected.
+	Satisfy(cav Caveat, params SatisfyParams) error
+}
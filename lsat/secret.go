@@ -0,0 +1,5 @@
+package lsat
+
+// SecretSize is the length in bytes of the shared secret used to sign and
+// verify the macaroon portion of an LSAT.
+const SecretSize = 32
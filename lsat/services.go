@@ -0,0 +1,90 @@
+package lsat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+// Tier signifies the tier of access that a service caveat grants to the
+// bearer of the LSAT.
+type Tier uint8
+
+const (
+	// BaseTier is the default tier granted to a service unless otherwise
+	// specified.
+	BaseTier Tier = 0
+)
+
+// servicesCaveatCondition is the name of the first-party caveat condition
+// used to restrict an LSAT to a set of services.
+const servicesCaveatCondition = "services"
+
+// Service represents a service that can be accessed with a valid LSAT.
+type Service struct {
+	// Name is the name of the service.
+	Name string
+
+	// Tier is the tier of access granted for the service.
+	Tier Tier
+
+	// Timeout, if non-zero, is how long after minting an LSAT scoped to
+	// this service should remain valid. The mint enforces this by
+	// attaching a valid_until caveat set to mint time plus Timeout.
+	Timeout time.Duration
+}
+
+// NewServicesCaveat creates a first-party caveat that restricts an LSAT to
+// only the given set of services.
+func NewServicesCaveat(services ...Service) (macaroon.Caveat, error) {
+	if len(services) == 0 {
+		return macaroon.Caveat{}, fmt.Errorf("at least one service " +
+			"must be specified")
+	}
+
+	parts := make([]string, 0, len(services))
+	for _, service := range services {
+		parts = append(parts, fmt.Sprintf(
+			"%s:%d", service.Name, service.Tier,
+		))
+	}
+
+	condition := fmt.Sprintf(
+		"%s=%s", servicesCaveatCondition, strings.Join(parts, ","),
+	)
+	return macaroon.Caveat{Id: []byte(condition)}, nil
+}
+
+// ParseServicesCaveat parses the services encoded within a services caveat
+// condition.
+func ParseServicesCaveat(id string) ([]Service, error) {
+	prefix := servicesCaveatCondition + "="
+	if !strings.HasPrefix(id, prefix) {
+		return nil, fmt.Errorf("not a services caveat")
+	}
+
+	raw := strings.TrimPrefix(id, prefix)
+	parts := strings.Split(raw, ",")
+	services := make([]Service, 0, len(parts))
+	for _, part := range parts {
+		nameTier := strings.SplitN(part, ":", 2)
+		if len(nameTier) != 2 {
+			return nil, fmt.Errorf("invalid service entry %q", part)
+		}
+
+		tier, err := strconv.ParseUint(nameTier[1], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tier in %q: %v", part, err)
+		}
+
+		services = append(services, Service{
+			Name: nameTier[0],
+			Tier: Tier(tier),
+		})
+	}
+
+	return services, nil
+}
@@ -0,0 +1,52 @@
+package mint
+
+import "github.com/lightninglabs/aperture/lsat"
+
+// Config houses all of the items required for the mint to carry out its
+// duties: minting fresh LSATs and verifying existing ones.
+type Config struct {
+	// Secrets is the store the mint uses to persist and retrieve the
+	// secrets used to sign and verify LSATs.
+	Secrets SecretStore
+
+	// Challenger is used to present the client with a payment challenge
+	// that must be satisfied before an LSAT is considered valid.
+	Challenger Challenger
+
+	// ServiceLimiter provides the caveats that should be attached to an
+	// LSAT for the services it's being minted for.
+	ServiceLimiter ServiceLimiter
+
+	// KeyForPseudoRandomness is the long-lived key used to derive the
+	// per-LSAT secret deterministically from its identifier. It's used
+	// as key version 0 if Keys is left unset, for backwards
+	// compatibility with mints that don't need key rotation.
+	KeyForPseudoRandomness []byte
+
+	// Keys manages the set of key versions available to derive LSAT
+	// secrets, enabling rotation of KeyForPseudoRandomness without
+	// invalidating outstanding tokens. If left nil, a Keyset containing
+	// only KeyForPseudoRandomness as key version 0 is used.
+	Keys *Keyset
+
+	// Recorder, if set, is notified of every LSAT minted so that it can
+	// be tracked for later introspection (e.g. by an admin API). It is
+	// optional.
+	Recorder Recorder
+
+	// Caveats are additional first-party caveats attached to every LSAT
+	// minted, on top of whatever the services or satisfier-specific
+	// helpers add.
+	Caveats []lsat.Caveat
+
+	// Satisfiers registers additional (or overriding) Satisfier
+	// implementations by caveat condition name, on top of the built-in
+	// ones the mint always understands (valid_until, ip_range,
+	// ip_range_not, capabilities, ratelimit).
+	Satisfiers map[string]lsat.Satisfier
+
+	// RateLimiter backs the built-in "ratelimit" caveat satisfier. If left
+	// nil, any LSAT carrying a ratelimit caveat fails verification rather
+	// than being let through unenforced.
+	RateLimiter RateLimiter
+}
@@ -0,0 +1,96 @@
+package mint
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightningnetwork/lnd/lntypes"
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+// ErrSecretNotFound is returned by a SecretStore when no secret exists for a
+// given identifier hash, either because it was never created or because it
+// has since been revoked.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore is the interface the mint relies on to persist and retrieve the
+// per-LSAT secrets used to sign and verify macaroons. Implementations back
+// this with different storage engines (etcd, bbolt, Postgres, etc.).
+type SecretStore interface {
+	// NewSecret creates a new secret for the given identifier hash,
+	// persists it, and returns it.
+	NewSecret(ctx context.Context, id [sha256.Size]byte) ([lsat.SecretSize]byte, error)
+
+	// GetSecret returns the secret associated with the given identifier
+	// hash, or ErrSecretNotFound if none exists.
+	GetSecret(ctx context.Context, id [sha256.Size]byte) ([lsat.SecretSize]byte, error)
+
+	// RevokeSecret removes the secret associated with the given
+	// identifier hash, if any, rendering any LSAT minted with it
+	// permanently invalid.
+	RevokeSecret(ctx context.Context, id [sha256.Size]byte) error
+}
+
+// Challenger is the interface the mint relies on to produce payment
+// challenges (Lightning invoices) that must be settled before an LSAT is
+// considered valid.
+type Challenger interface {
+	// NewChallenge returns a new Lightning invoice of the given price
+	// along with its payment hash.
+	NewChallenge(price int64) (string, lntypes.Hash, error)
+}
+
+// TokenInfo summarizes an LSAT at the time it was minted, for the benefit of
+// callers that want to keep a record of every token issued (e.g. an admin
+// introspection API) without needing to understand the macaroon's internal
+// encoding.
+type TokenInfo struct {
+	// IDHash is the identifier hash used to key the token's secret in the
+	// SecretStore.
+	IDHash [sha256.Size]byte
+
+	// Identifier is the decoded identifier encoded in the macaroon.
+	Identifier lsat.Identifier
+
+	// Services are the services the token is authorized to access. An
+	// empty slice indicates an admin token authorized for every service.
+	Services []lsat.Service
+
+	// MintTime is when the token was minted.
+	MintTime time.Time
+}
+
+// Recorder is the interface the mint relies on to record a TokenInfo entry
+// for every LSAT it mints. It's optional: a Config with no Recorder simply
+// skips recording.
+type Recorder interface {
+	// RecordToken persists the given TokenInfo.
+	RecordToken(ctx context.Context, info *TokenInfo) error
+}
+
+// RateLimiter is the interface the mint relies on for the built-in
+// "ratelimit" caveat satisfier to track call counts per bucket in a way
+// that survives restarts. Implementations are typically backed by the same
+// storage engine as the SecretStore.
+type RateLimiter interface {
+	// Allow increments the call counter identified by key and reports
+	// whether the caller is still within limit calls for the current
+	// window.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+}
+
+// ServiceLimiter is the interface the mint relies on to determine the
+// additional caveats that should be attached to an LSAT for the given set of
+// services.
+type ServiceLimiter interface {
+	// ServiceCapabilities returns the capability caveats that should be
+	// applied to an LSAT for the given services.
+	ServiceCapabilities(ctx context.Context, services ...lsat.Service) ([]macaroon.Caveat, error)
+
+	// ServiceTimeouts returns the timeout caveats that should be applied
+	// to an LSAT for the given services.
+	ServiceTimeouts(ctx context.Context, services ...lsat.Service) ([]macaroon.Caveat, error)
+}
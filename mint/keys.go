@@ -0,0 +1,129 @@
+package mint
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrKeyRetired is returned by VerifyLSAT when the LSAT presented was
+// minted under a key version whose NotAfter has passed.
+var ErrKeyRetired = errors.New("signing key retired")
+
+// KeyVersion is a single version of the mint's long-lived
+// KeyForPseudoRandomness, identified by ID. A Keyset can hold several
+// versions at once so that LSATs minted under an older key continue to
+// verify until that version is retired.
+type KeyVersion struct {
+	// ID uniquely identifies this key version. It's stamped into the
+	// KeyID field of every identifier minted while this version is
+	// active.
+	ID uint32
+
+	// Secret is the key material used to derive LSAT secrets while this
+	// version is active.
+	Secret []byte
+
+	// NotAfter is the time after which this key version is considered
+	// retired: LSATs minted under it stop verifying, and a sweep may
+	// revoke their stored secrets outright. The zero value means the
+	// key never expires.
+	NotAfter time.Time
+}
+
+// retired reports whether this key version's NotAfter has passed as of now.
+func (k KeyVersion) retired(now time.Time) bool {
+	return !k.NotAfter.IsZero() && !now.Before(k.NotAfter)
+}
+
+// Keyset manages the KeyVersions a mint may use to derive LSAT secrets,
+// allowing the active signing key to be rotated without invalidating every
+// outstanding token.
+type Keyset struct {
+	mu       sync.RWMutex
+	keys     map[uint32]KeyVersion
+	activeID uint32
+}
+
+// NewKeyset creates a Keyset whose only, active key version is initial.
+func NewKeyset(initial KeyVersion) *Keyset {
+	return &Keyset{
+		keys:     map[uint32]KeyVersion{initial.ID: initial},
+		activeID: initial.ID,
+	}
+}
+
+// AddKey registers key and makes it the active version used to mint new
+// LSATs, without affecting any previously registered version.
+func (s *Keyset) AddKey(key KeyVersion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = key
+	s.activeID = key.ID
+}
+
+// RetireKey sets notAfter on the key version identified by id, after which
+// LSATs minted under it fail verification with ErrKeyRetired.
+func (s *Keyset) RetireKey(id uint32, notAfter time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return fmt.Errorf("unknown key version %d", id)
+	}
+
+	key.NotAfter = notAfter
+	s.keys[id] = key
+	return nil
+}
+
+// ActiveKey returns the key version currently used to mint new LSATs.
+func (s *Keyset) ActiveKey() KeyVersion {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.keys[s.activeID]
+}
+
+// KeyByID returns the key version registered under id, if any.
+func (s *Keyset) KeyByID(id uint32) (KeyVersion, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[id]
+	return key, ok
+}
+
+// ExpiredKeyIDs returns the IDs of every non-active key version whose
+// NotAfter has passed as of now.
+func (s *Keyset) ExpiredKeyIDs(now time.Time) []uint32 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []uint32
+	for id, key := range s.keys {
+		if id == s.activeID {
+			continue
+		}
+		if key.retired(now) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// KeyedSecretLister enumerates the identifier hash of every secret minted
+// under a given key version. Implementing it alongside Recorder lets a
+// token registry (e.g. the admin package's Registry) be swept for secrets
+// that need revoking once their key version is fully retired.
+type KeyedSecretLister interface {
+	// ListByKeyID returns the identifier hash of every token minted
+	// under the given key version.
+	ListByKeyID(ctx context.Context, keyID uint32) ([][sha256.Size]byte, error)
+}
@@ -0,0 +1,135 @@
+package mint
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestKeyRotation mints an LSAT under key version 1, rotates the active key
+// to version 2, confirms both tokens still verify, then retires version 1
+// and confirms the token minted under it now fails with ErrKeyRetired
+// while the version 2 token remains valid.
+func TestKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	keys := NewKeyset(KeyVersion{ID: 1, Secret: []byte("key-version-one")})
+	m := New(&Config{
+		Secrets:        newMockSecretStore(),
+		Challenger:     newMockChallenger(),
+		ServiceLimiter: newMockServiceLimiter(),
+		Keys:           keys,
+	})
+
+	macV1, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT under key v1: %v", err)
+	}
+
+	paramsV1 := &VerificationParams{
+		Macaroon:      macV1,
+		TargetService: testService.Name,
+	}
+	if err := m.VerifyLSAT(ctx, paramsV1); err != nil {
+		t.Fatalf("unable to verify LSAT minted under key v1: %v", err)
+	}
+
+	// Rotate to a new active key version.
+	keys.AddKey(KeyVersion{ID: 2, Secret: []byte("key-version-two")})
+
+	macV2, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT under key v2: %v", err)
+	}
+
+	paramsV2 := &VerificationParams{
+		Macaroon:      macV2,
+		TargetService: testService.Name,
+	}
+	if err := m.VerifyLSAT(ctx, paramsV2); err != nil {
+		t.Fatalf("unable to verify LSAT minted under key v2: %v", err)
+	}
+
+	// The v1 token must still verify while its key version isn't retired.
+	if err := m.VerifyLSAT(ctx, paramsV1); err != nil {
+		t.Fatalf("LSAT minted under key v1 should still verify: %v", err)
+	}
+
+	// Retire key v1 in the past; its tokens must now fail distinctly
+	// with ErrKeyRetired, while v2 tokens are unaffected.
+	if err := keys.RetireKey(1, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("unable to retire key v1: %v", err)
+	}
+
+	if err := m.VerifyLSAT(ctx, paramsV1); err != ErrKeyRetired {
+		t.Fatalf("expected ErrKeyRetired, got %v", err)
+	}
+	if err := m.VerifyLSAT(ctx, paramsV2); err != nil {
+		t.Fatalf("LSAT minted under key v2 should still verify: %v", err)
+	}
+}
+
+// TestSweepExpiredKeys confirms that SweepExpiredKeys revokes the stored
+// secret of every token minted under a fully retired key version, using a
+// lister to enumerate them, while leaving tokens under other versions
+// untouched.
+func TestSweepExpiredKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	keys := NewKeyset(KeyVersion{ID: 1, Secret: []byte("key-version-one")})
+	secrets := newMockSecretStore()
+	lister := newMockKeyedLister()
+
+	m := New(&Config{
+		Secrets:        secrets,
+		Challenger:     newMockChallenger(),
+		ServiceLimiter: newMockServiceLimiter(),
+		Keys:           keys,
+	})
+
+	macV1, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT under key v1: %v", err)
+	}
+	lister.record(macV1, 1)
+
+	keys.AddKey(KeyVersion{ID: 2, Secret: []byte("key-version-two")})
+
+	macV2, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT under key v2: %v", err)
+	}
+	lister.record(macV2, 2)
+
+	if err := keys.RetireKey(1, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("unable to retire key v1: %v", err)
+	}
+
+	revoked, err := m.SweepExpiredKeys(ctx, lister)
+	if err != nil {
+		t.Fatalf("unable to sweep expired keys: %v", err)
+	}
+	if revoked != 1 {
+		t.Fatalf("expected 1 token revoked, got %d", revoked)
+	}
+
+	paramsV1 := &VerificationParams{
+		Macaroon:      macV1,
+		TargetService: testService.Name,
+	}
+	if err := m.VerifyLSAT(ctx, paramsV1); err != ErrSecretNotFound {
+		t.Fatalf("expected swept v1 token to be revoked, got %v", err)
+	}
+
+	paramsV2 := &VerificationParams{
+		Macaroon:      macV2,
+		TargetService: testService.Name,
+	}
+	if err := m.VerifyLSAT(ctx, paramsV2); err != nil {
+		t.Fatalf("v2 token should be unaffected by the sweep: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package mint
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightningnetwork/lnd/lntypes"
+	macaroon "gopkg.in/macaroon.v2"
+)
+
+// testPreimage is the preimage used throughout the test suite to simulate a
+// settled Lightning payment.
+var testPreimage = lntypes.Preimage{1, 2, 3}
+
+// mockSecretStore is an in-memory SecretStore used for testing.
+type mockSecretStore struct {
+	mu      sync.Mutex
+	secrets map[[sha256.Size]byte][lsat.SecretSize]byte
+}
+
+func newMockSecretStore() *mockSecretStore {
+	return &mockSecretStore{
+		secrets: make(map[[sha256.Size]byte][lsat.SecretSize]byte),
+	}
+}
+
+func (s *mockSecretStore) NewSecret(_ context.Context,
+	id [sha256.Size]byte) ([lsat.SecretSize]byte, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var secret [lsat.SecretSize]byte
+	copy(secret[:], id[:])
+	s.secrets[id] = secret
+	return secret, nil
+}
+
+func (s *mockSecretStore) GetSecret(_ context.Context,
+	id [sha256.Size]byte) ([lsat.SecretSize]byte, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, ok := s.secrets[id]
+	if !ok {
+		return secret, ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+func (s *mockSecretStore) RevokeSecret(_ context.Context, id [sha256.Size]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.secrets, id)
+	return nil
+}
+
+// mockChallenger is a Challenger that always returns a canned invoice,
+// without requiring a connection to an actual lnd node.
+type mockChallenger struct{}
+
+func newMockChallenger() *mockChallenger {
+	return &mockChallenger{}
+}
+
+func (c *mockChallenger) NewChallenge(price int64) (string, lntypes.Hash, error) {
+	return "lnbc1...mockinvoice", lntypes.Hash(sha256.Sum256(testPreimage[:])), nil
+}
+
+// mockServiceLimiter is a ServiceLimiter that attaches no additional
+// caveats beyond what the mint already adds by default.
+type mockServiceLimiter struct{}
+
+func newMockServiceLimiter() *mockServiceLimiter {
+	return &mockServiceLimiter{}
+}
+
+func (l *mockServiceLimiter) ServiceCapabilities(_ context.Context,
+	_ ...lsat.Service) ([]macaroon.Caveat, error) {
+
+	return nil, nil
+}
+
+func (l *mockServiceLimiter) ServiceTimeouts(_ context.Context,
+	_ ...lsat.Service) ([]macaroon.Caveat, error) {
+
+	return nil, nil
+}
+
+// mockKeyedLister is a KeyedSecretLister that tracks which key version each
+// test-minted token belongs to, used to exercise SweepExpiredKeys without a
+// full admin.Registry.
+type mockKeyedLister struct {
+	byKeyID map[uint32][][sha256.Size]byte
+}
+
+func newMockKeyedLister() *mockKeyedLister {
+	return &mockKeyedLister{byKeyID: make(map[uint32][][sha256.Size]byte)}
+}
+
+// record associates mac's identifier hash with the given key version.
+func (l *mockKeyedLister) record(mac *macaroon.Macaroon, keyID uint32) {
+	idHash := sha256.Sum256(mac.Id())
+	l.byKeyID[keyID] = append(l.byKeyID[keyID], idHash)
+}
+
+func (l *mockKeyedLister) ListByKeyID(_ context.Context,
+	keyID uint32) ([][sha256.Size]byte, error) {
+
+	return l.byKeyID[keyID], nil
+}
+
+// mockRateLimiter is an in-memory RateLimiter used to test the ratelimit
+// caveat satisfier without depending on a real storage backend.
+type mockRateLimiter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newMockRateLimiter() *mockRateLimiter {
+	return &mockRateLimiter{counts: make(map[string]int)}
+}
+
+func (l *mockRateLimiter) Allow(_ context.Context, key string, limit int,
+	_ time.Duration) (bool, error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.counts[key]++
+	return l.counts[key] <= limit, nil
+}
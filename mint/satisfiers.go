@@ -0,0 +1,157 @@
+package mint
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lightninglabs/aperture/lsat"
+)
+
+// defaultSatisfiers returns the built-in Satisfier registry the mint always
+// understands, keyed by caveat condition name. rateLimiter may be nil, in
+// which case the registered ratelimit satisfier rejects every LSAT carrying
+// a ratelimit caveat instead of silently letting it through unenforced.
+func defaultSatisfiers(rateLimiter RateLimiter) map[string]lsat.Satisfier {
+	satisfiers := map[string]lsat.Satisfier{
+		lsat.CondValidUntil:   validUntilSatisfier{},
+		lsat.CondIPRange:      ipRangeSatisfier{},
+		lsat.CondIPRangeNot:   ipRangeNotSatisfier{},
+		lsat.CondCapabilities: capabilitiesSatisfier{},
+		lsat.CondRateLimit:    rateLimitSatisfier{limiter: rateLimiter},
+	}
+	return satisfiers
+}
+
+// validUntilSatisfier enforces the "valid_until=<unix>" caveat.
+type validUntilSatisfier struct{}
+
+func (validUntilSatisfier) Name() string { return lsat.CondValidUntil }
+
+func (validUntilSatisfier) Satisfy(cav lsat.Caveat, params lsat.SatisfyParams) error {
+	raw := strings.TrimPrefix(string(cav.Id), lsat.CondValidUntil+"=")
+	expiry, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid valid_until caveat: %v", err)
+	}
+
+	if params.Now.After(time.Unix(expiry, 0)) {
+		return fmt.Errorf("LSAT expired at %d", expiry)
+	}
+
+	return nil
+}
+
+// ipRangeSatisfier enforces the "ip_range=<CIDR>" caveat.
+type ipRangeSatisfier struct{}
+
+func (ipRangeSatisfier) Name() string { return lsat.CondIPRange }
+
+func (ipRangeSatisfier) Satisfy(cav lsat.Caveat, params lsat.SatisfyParams) error {
+	raw := strings.TrimPrefix(string(cav.Id), lsat.CondIPRange+"=")
+	_, network, err := net.ParseCIDR(raw)
+	if err != nil {
+		return fmt.Errorf("invalid ip_range caveat: %v", err)
+	}
+
+	if params.ClientIP == nil || !network.Contains(params.ClientIP) {
+		return fmt.Errorf("client IP %s not within %s", params.ClientIP, raw)
+	}
+
+	return nil
+}
+
+// ipRangeNotSatisfier enforces the "ip_range_not=<CIDR>" caveat.
+type ipRangeNotSatisfier struct{}
+
+func (ipRangeNotSatisfier) Name() string { return lsat.CondIPRangeNot }
+
+func (ipRangeNotSatisfier) Satisfy(cav lsat.Caveat, params lsat.SatisfyParams) error {
+	raw := strings.TrimPrefix(string(cav.Id), lsat.CondIPRangeNot+"=")
+	_, network, err := net.ParseCIDR(raw)
+	if err != nil {
+		return fmt.Errorf("invalid ip_range_not caveat: %v", err)
+	}
+
+	if params.ClientIP != nil && network.Contains(params.ClientIP) {
+		return fmt.Errorf("client IP %s within excluded range %s",
+			params.ClientIP, raw)
+	}
+
+	return nil
+}
+
+// capabilitiesSatisfier enforces the "capabilities=<csv>" caveat.
+type capabilitiesSatisfier struct{}
+
+func (capabilitiesSatisfier) Name() string { return lsat.CondCapabilities }
+
+func (capabilitiesSatisfier) Satisfy(cav lsat.Caveat, params lsat.SatisfyParams) error {
+	// A caveat restricting the LSAT to specific capabilities can only be
+	// checked if the caller told us which capability is being exercised.
+	// Treating a missing Capability as "unrestricted" would let any
+	// caller that forgets to populate it silently bypass this caveat, so
+	// an empty Capability fails closed instead.
+	if params.Capability == "" {
+		return fmt.Errorf("capabilities caveat present but no " +
+			"capability was supplied to check against")
+	}
+
+	raw := strings.TrimPrefix(string(cav.Id), lsat.CondCapabilities+"=")
+	for _, capability := range strings.Split(raw, ",") {
+		if capability == params.Capability {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("capability %q not authorized", params.Capability)
+}
+
+// rateLimitSatisfier enforces the "ratelimit=<n>/<window>" caveat. limiter
+// may be nil, in which case Satisfy always fails closed: a caveat the mint
+// recognizes but has no RateLimiter configured to enforce must reject the
+// LSAT rather than pass it through unenforced.
+type rateLimitSatisfier struct {
+	limiter RateLimiter
+}
+
+func (rateLimitSatisfier) Name() string { return lsat.CondRateLimit }
+
+func (s rateLimitSatisfier) Satisfy(cav lsat.Caveat, params lsat.SatisfyParams) error {
+	if s.limiter == nil {
+		return fmt.Errorf("ratelimit caveat present but no " +
+			"RateLimiter configured")
+	}
+
+	raw := strings.TrimPrefix(string(cav.Id), lsat.CondRateLimit+"=")
+	parts := strings.SplitN(raw, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid ratelimit caveat %q", raw)
+	}
+
+	limit, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid ratelimit count: %v", err)
+	}
+	window, err := time.ParseDuration(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid ratelimit window: %v", err)
+	}
+
+	// Bucket per token: the token ID, unique to this LSAT, is combined
+	// with the caveat's raw bytes so that two distinct tokens carrying an
+	// identical ratelimit caveat (e.g. attached uniformly via
+	// Config.Caveats) don't share a counter.
+	key := fmt.Sprintf("%x:%s", params.TokenID[:], cav.Id)
+	allowed, err := s.limiter.Allow(params.Ctx, key, limit, window)
+	if err != nil {
+		return fmt.Errorf("unable to check rate limit: %v", err)
+	}
+	if !allowed {
+		return fmt.Errorf("rate limit of %d/%s exceeded", limit, window)
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package mint
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lightninglabs/aperture/lsat"
+)
+
+// TestValidUntilCaveatStacking ensures that when multiple valid_until
+// caveats are stacked on an LSAT, the tightest (earliest) one determines
+// whether the token has expired.
+func TestValidUntilCaveatStacking(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := New(&Config{
+		Secrets:        newMockSecretStore(),
+		Challenger:     newMockChallenger(),
+		ServiceLimiter: newMockServiceLimiter(),
+	})
+
+	mac, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT: %v", err)
+	}
+
+	params := &VerificationParams{
+		Macaroon:      mac,
+		TargetService: testService.Name,
+	}
+
+	// Attach two valid_until caveats that are both still in the future;
+	// verification should succeed since neither has expired.
+	farFuture := lsat.NewValidUntilCaveat(time.Now().Add(time.Hour))
+	nearFuture := lsat.NewValidUntilCaveat(time.Now().Add(time.Minute))
+	if err := lsat.AddFirstPartyCaveats(mac, farFuture, nearFuture); err != nil {
+		t.Fatalf("unable to add caveats: %v", err)
+	}
+	if err := m.VerifyLSAT(ctx, params); err != nil {
+		t.Fatalf("unable to verify LSAT with two future expiries: %v", err)
+	}
+
+	// Now also attach one that already expired. Even though the other
+	// two caveats are still satisfied, the tightest (already expired)
+	// one must cause verification to fail.
+	expired := lsat.NewValidUntilCaveat(time.Now().Add(-time.Minute))
+	if err := lsat.AddFirstPartyCaveats(mac, expired); err != nil {
+		t.Fatalf("unable to add expired caveat: %v", err)
+	}
+	err = m.VerifyLSAT(ctx, params)
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Fatalf("expected expired LSAT to be rejected, got %v", err)
+	}
+}
+
+// TestIPRangeCaveat ensures that an LSAT restricted to a given client IP
+// range is rejected when presented from outside of that range.
+func TestIPRangeCaveat(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ipCaveat := lsat.NewIPRangeCaveat("10.0.0.0/24")
+	m := New(&Config{
+		Secrets:        newMockSecretStore(),
+		Challenger:     newMockChallenger(),
+		ServiceLimiter: newMockServiceLimiter(),
+		Caveats:        []lsat.Caveat{ipCaveat},
+	})
+
+	mac, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT: %v", err)
+	}
+
+	allowedParams := &VerificationParams{
+		Macaroon:      mac,
+		TargetService: testService.Name,
+		ClientIP:      net.ParseIP("10.0.0.42"),
+	}
+	if err := m.VerifyLSAT(ctx, allowedParams); err != nil {
+		t.Fatalf("expected LSAT to be authorized from within the "+
+			"range: %v", err)
+	}
+
+	deniedParams := &VerificationParams{
+		Macaroon:      mac,
+		TargetService: testService.Name,
+		ClientIP:      net.ParseIP("192.168.1.1"),
+	}
+	err = m.VerifyLSAT(ctx, deniedParams)
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Fatalf("expected LSAT to be rejected from outside the "+
+			"range, got %v", err)
+	}
+}
+
+// TestCapabilitiesCaveatAttenuation ensures that an LSAT's capabilities can
+// only be narrowed by stacking additional capabilities caveats, mirroring
+// TestDemotedServicesLSAT's treatment of the services caveat.
+func TestCapabilitiesCaveatAttenuation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	capsCaveat := lsat.NewCapabilitiesCaveat("read", "write")
+	m := New(&Config{
+		Secrets:        newMockSecretStore(),
+		Challenger:     newMockChallenger(),
+		ServiceLimiter: newMockServiceLimiter(),
+		Caveats:        []lsat.Caveat{capsCaveat},
+	})
+
+	mac, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT: %v", err)
+	}
+
+	readParams := &VerificationParams{
+		Macaroon:      mac,
+		TargetService: testService.Name,
+		Capability:    "read",
+	}
+	writeParams := &VerificationParams{
+		Macaroon:      mac,
+		TargetService: testService.Name,
+		Capability:    "write",
+	}
+	if err := m.VerifyLSAT(ctx, readParams); err != nil {
+		t.Fatalf("unable to verify read capability: %v", err)
+	}
+	if err := m.VerifyLSAT(ctx, writeParams); err != nil {
+		t.Fatalf("unable to verify write capability: %v", err)
+	}
+
+	// Demote the token to read-only by stacking a narrower capabilities
+	// caveat.
+	demoted := lsat.NewCapabilitiesCaveat("read")
+	if err := lsat.AddFirstPartyCaveats(mac, demoted); err != nil {
+		t.Fatalf("unable to demote LSAT: %v", err)
+	}
+
+	if err := m.VerifyLSAT(ctx, readParams); err != nil {
+		t.Fatalf("expected read capability to still be authorized: %v", err)
+	}
+	err = m.VerifyLSAT(ctx, writeParams)
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Fatalf("expected write capability to be demoted, got %v", err)
+	}
+}
+
+// TestCapabilitiesCaveatFailsClosed ensures that an LSAT carrying a
+// capabilities caveat is rejected, rather than let through unrestricted, when
+// the caller omits Capability from VerificationParams.
+func TestCapabilitiesCaveatFailsClosed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	capsCaveat := lsat.NewCapabilitiesCaveat("read")
+	m := New(&Config{
+		Secrets:        newMockSecretStore(),
+		Challenger:     newMockChallenger(),
+		ServiceLimiter: newMockServiceLimiter(),
+		Caveats:        []lsat.Caveat{capsCaveat},
+	})
+
+	mac, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT: %v", err)
+	}
+
+	params := &VerificationParams{Macaroon: mac, TargetService: testService.Name}
+	err = m.VerifyLSAT(ctx, params)
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Fatalf("expected LSAT with an unchecked capabilities caveat "+
+			"to be rejected, got %v", err)
+	}
+}
+
+// TestRateLimitCaveatPerToken ensures that two distinct LSATs carrying an
+// identical ratelimit caveat (as they would if the caveat were attached
+// uniformly via Config.Caveats) are tracked in separate buckets, rather than
+// exhausting one shared counter.
+func TestRateLimitCaveatPerToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rateCaveat := lsat.Caveat{Id: []byte("ratelimit=1/1h")}
+	limiter := newMockRateLimiter()
+	m := New(&Config{
+		Secrets:        newMockSecretStore(),
+		Challenger:     newMockChallenger(),
+		ServiceLimiter: newMockServiceLimiter(),
+		Caveats:        []lsat.Caveat{rateCaveat},
+		RateLimiter:    limiter,
+	})
+
+	macA, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint first LSAT: %v", err)
+	}
+	macB, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint second LSAT: %v", err)
+	}
+
+	paramsA := &VerificationParams{Macaroon: macA, TargetService: testService.Name}
+	paramsB := &VerificationParams{Macaroon: macB, TargetService: testService.Name}
+
+	// Each token is allowed its own single call, even though both carry
+	// the exact same caveat bytes.
+	if err := m.VerifyLSAT(ctx, paramsA); err != nil {
+		t.Fatalf("expected first call on token A to be allowed: %v", err)
+	}
+	if err := m.VerifyLSAT(ctx, paramsB); err != nil {
+		t.Fatalf("expected first call on token B to be allowed: %v", err)
+	}
+
+	// A second call on either token should now be rejected, since each
+	// has its own 1/1h budget.
+	err = m.VerifyLSAT(ctx, paramsA)
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Fatalf("expected second call on token A to be rejected, got %v", err)
+	}
+}
+
+// TestRateLimitCaveatFailsClosed ensures that a ratelimit caveat is rejected,
+// rather than silently ignored, when the mint has no RateLimiter configured
+// to enforce it.
+func TestRateLimitCaveatFailsClosed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rateCaveat := lsat.Caveat{Id: []byte("ratelimit=1/1h")}
+	m := New(&Config{
+		Secrets:        newMockSecretStore(),
+		Challenger:     newMockChallenger(),
+		ServiceLimiter: newMockServiceLimiter(),
+		Caveats:        []lsat.Caveat{rateCaveat},
+	})
+
+	mac, _, err := m.MintLSAT(ctx, testService)
+	if err != nil {
+		t.Fatalf("unable to mint LSAT: %v", err)
+	}
+
+	params := &VerificationParams{Macaroon: mac, TargetService: testService.Name}
+	err = m.VerifyLSAT(ctx, params)
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Fatalf("expected LSAT with unenforceable ratelimit caveat to "+
+			"be rejected, got %v", err)
+	}
+}
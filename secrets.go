@@ -0,0 +1,71 @@
+package aperture
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/lightninglabs/aperture/store"
+)
+
+// minUserSeedLength is the minimum length a user-supplied seed must have
+// before it is accepted as input to verifyAndStretchKey.
+const minUserSeedLength = 8
+
+// keySize is the length in bytes of a stretched key.
+const keySize = 32
+
+// weakSeedSubstrings is a small blacklist of substrings that, if present in
+// a user-supplied seed, cause it to be rejected outright regardless of its
+// length. This catches the most common "I typed something long but obvious"
+// mistakes.
+var weakSeedSubstrings = []string{
+	"password",
+	"predictable",
+	"123456",
+}
+
+// secretStore is a mint.SecretStore backed by an etcd cluster. It is kept
+// as a thin wrapper around store.EtcdStore so that this package's existing
+// callers and tests don't need to be aware of the storage.backend
+// abstraction introduced in the store package.
+type secretStore struct {
+	*store.EtcdStore
+}
+
+// newSecretStore creates a new etcd-backed secret store using the given
+// client connection.
+func newSecretStore(client *clientv3.Client) *secretStore {
+	return &secretStore{EtcdStore: store.NewEtcdStore(client)}
+}
+
+// verifyAndStretchKey validates a user-supplied seed and stretches it into a
+// fixed-size key suitable for use as KeyForPseudoRandomness. It rejects
+// seeds that are too short or that match a known-weak pattern.
+func verifyAndStretchKey(seed string) ([keySize]byte, error) {
+	var key [keySize]byte
+
+	if len(seed) < minUserSeedLength {
+		return key, fmt.Errorf("seed must be at least %d characters",
+			minUserSeedLength)
+	}
+
+	lower := strings.ToLower(seed)
+	for _, weak := range weakSeedSubstrings {
+		if strings.Contains(lower, weak) {
+			return key, fmt.Errorf("seed is too predictable")
+		}
+	}
+
+	// Stretch the seed with 4096 additional rounds of SHA-256 so that a
+	// short-ish but otherwise acceptable passphrase still yields a key
+	// that's expensive to brute-force.
+	digest := sha256.Sum256([]byte(seed))
+	for i := 0; i < 4096; i++ {
+		digest = sha256.Sum256(digest[:])
+	}
+
+	copy(key[:], digest[:])
+	return key, nil
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightninglabs/aperture/mint"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// bboltDBFileName is the name of the database file created within the
+// configured directory.
+const bboltDBFileName = "aperture_secrets.db"
+
+// secretsBucket is the name of the top-level bbolt bucket holding secrets.
+var secretsBucket = []byte("secrets")
+
+// BboltStore is a mint.SecretStore backed by a local bbolt database,
+// suitable for a small, single-binary deployment that doesn't need to
+// share state with other aperture instances.
+type BboltStore struct {
+	db *bbolt.DB
+}
+
+// NewBboltStore opens (creating if necessary) a bbolt database within dir
+// and returns a BboltStore backed by it.
+func NewBboltStore(dir string) (*BboltStore, error) {
+	dbPath := filepath.Join(dir, bboltDBFileName)
+	db, err := bbolt.Open(dbPath, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open bbolt db: %v", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(secretsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create bucket: %v", err)
+	}
+
+	return &BboltStore{db: db}, nil
+}
+
+// Close releases the underlying bbolt database file.
+func (s *BboltStore) Close() error {
+	return s.db.Close()
+}
+
+// NewSecret generates a new random secret for the given identifier hash and
+// persists it to the bbolt database.
+func (s *BboltStore) NewSecret(_ context.Context,
+	id [sha256.Size]byte) ([lsat.SecretSize]byte, error) {
+
+	var secret [lsat.SecretSize]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return secret, fmt.Errorf("unable to generate secret: %v", err)
+	}
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(secretsBucket)
+		return bucket.Put(id[:], secret[:])
+	})
+	if err != nil {
+		return secret, fmt.Errorf("unable to store secret: %v", err)
+	}
+
+	return secret, nil
+}
+
+// GetSecret retrieves the secret for the given identifier hash.
+func (s *BboltStore) GetSecret(_ context.Context,
+	id [sha256.Size]byte) ([lsat.SecretSize]byte, error) {
+
+	var secret [lsat.SecretSize]byte
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(secretsBucket)
+		v := bucket.Get(id[:])
+		if v == nil {
+			return mint.ErrSecretNotFound
+		}
+		copy(secret[:], v)
+		return nil
+	})
+	if err != nil {
+		return secret, err
+	}
+
+	return secret, nil
+}
+
+// PutSecret writes an already-derived secret value for the given identifier
+// hash, overwriting any existing value. It implements the secretWriter
+// interface used by the migration tooling.
+func (s *BboltStore) PutSecret(_ context.Context, id [sha256.Size]byte,
+	secret [lsat.SecretSize]byte) error {
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(secretsBucket)
+		return bucket.Put(id[:], secret[:])
+	})
+}
+
+// RevokeSecret deletes the secret for the given identifier hash, if any.
+func (s *BboltStore) RevokeSecret(_ context.Context, id [sha256.Size]byte) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(secretsBucket)
+		return bucket.Delete(id[:])
+	})
+}
+
+// ListSecretIDs returns the identifier hash of every secret currently
+// persisted in the bbolt database. It implements the Enumerable interface.
+func (s *BboltStore) ListSecretIDs(_ context.Context) ([][32]byte, error) {
+	var ids [][32]byte
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(secretsBucket)
+		return bucket.ForEach(func(k, _ []byte) error {
+			if len(k) != 32 {
+				return nil
+			}
+			var id [32]byte
+			copy(id[:], k)
+			ids = append(ids, id)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,151 @@
+// The shared conformance suite below is run against every backend. Memory
+// and bbolt require no external infrastructure, so they always run. The
+// etcd backend is instead covered by the root package's existing
+// integration-style test (see etcdSetup in secrets_test.go), which spins up
+// an embedded cluster. Postgres can't be embedded the same way, so
+// TestPostgresStoreConformance runs the suite against a real instance
+// pointed to by postgresTestDSN, and is skipped when that isn't set.
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"testing"
+
+	"github.com/lightninglabs/aperture/mint"
+)
+
+// testSecretStore runs a battery of behavioral checks against store that
+// every mint.SecretStore implementation, regardless of backend, must
+// satisfy.
+func testSecretStore(t *testing.T, store mint.SecretStore) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	var id [sha256.Size]byte
+	copy(id[:], []byte("conformance-test-identifier-hash"))
+
+	// No secret should exist yet for a fresh identifier.
+	if _, err := store.GetSecret(ctx, id); err != mint.ErrSecretNotFound {
+		t.Fatalf("expected ErrSecretNotFound, got %v", err)
+	}
+
+	// Creating one should make it retrievable with the same value.
+	secret, err := store.NewSecret(ctx, id)
+	if err != nil {
+		t.Fatalf("unable to create secret: %v", err)
+	}
+	got, err := store.GetSecret(ctx, id)
+	if err != nil {
+		t.Fatalf("unable to fetch secret: %v", err)
+	}
+	if got != secret {
+		t.Fatalf("expected secret %x, got %x", secret, got)
+	}
+
+	// Revoking it should make it disappear again.
+	if err := store.RevokeSecret(ctx, id); err != nil {
+		t.Fatalf("unable to revoke secret: %v", err)
+	}
+	if _, err := store.GetSecret(ctx, id); err != mint.ErrSecretNotFound {
+		t.Fatalf("expected ErrSecretNotFound after revocation, got %v", err)
+	}
+
+	// Revoking a secret that was never created should be a no-op.
+	var neverCreated [sha256.Size]byte
+	copy(neverCreated[:], []byte("never-created-identifier-hash"))
+	if err := store.RevokeSecret(ctx, neverCreated); err != nil {
+		t.Fatalf("revoking a nonexistent secret should not error: %v", err)
+	}
+}
+
+// TestMemoryStoreConformance runs the shared conformance suite against the
+// in-memory backend.
+func TestMemoryStoreConformance(t *testing.T) {
+	t.Parallel()
+	testSecretStore(t, NewMemoryStore())
+}
+
+// TestBboltStoreConformance runs the shared conformance suite against the
+// bbolt backend.
+func TestBboltStoreConformance(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewBboltStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unable to create bbolt store: %v", err)
+	}
+	defer store.Close()
+
+	testSecretStore(t, store)
+}
+
+// postgresTestDSN is the environment variable used to point
+// TestPostgresStoreConformance at a live Postgres instance. It's left unset
+// in environments that don't provision one, in which case the test is
+// skipped rather than failed.
+const postgresTestDSN = "APERTURE_POSTGRES_TEST_DSN"
+
+// TestPostgresStoreConformance runs the shared conformance suite against the
+// Postgres backend, plus checks specific to its ON CONFLICT upsert and
+// ListSecretIDs enumeration. Skipped unless postgresTestDSN is set.
+func TestPostgresStoreConformance(t *testing.T) {
+	dsn := os.Getenv(postgresTestDSN)
+	if dsn == "" {
+		t.Skipf("%s not set; skipping Postgres conformance test",
+			postgresTestDSN)
+	}
+
+	store, err := NewPostgresStore(dsn)
+	if err != nil {
+		t.Fatalf("unable to create postgres store: %v", err)
+	}
+	defer store.Close()
+
+	testSecretStore(t, store)
+
+	ctx := context.Background()
+
+	var id [sha256.Size]byte
+	copy(id[:], []byte("postgres-conformance-upsert-id"))
+	defer store.RevokeSecret(ctx, id)
+
+	first, err := store.NewSecret(ctx, id)
+	if err != nil {
+		t.Fatalf("unable to create secret: %v", err)
+	}
+
+	// NewSecret's ON CONFLICT upsert should overwrite the existing row
+	// rather than fail when called again for the same identifier hash.
+	second, err := store.NewSecret(ctx, id)
+	if err != nil {
+		t.Fatalf("unable to create secret a second time: %v", err)
+	}
+	if first == second {
+		t.Fatalf("expected a freshly generated secret on re-creation")
+	}
+	got, err := store.GetSecret(ctx, id)
+	if err != nil {
+		t.Fatalf("unable to fetch secret: %v", err)
+	}
+	if got != second {
+		t.Fatalf("expected upsert to overwrite the stored secret")
+	}
+
+	ids, err := store.ListSecretIDs(ctx)
+	if err != nil {
+		t.Fatalf("unable to list secret ids: %v", err)
+	}
+	found := false
+	for _, listed := range ids {
+		if listed == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("expected %x to be present in ListSecretIDs", id)
+	}
+}
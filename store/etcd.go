@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightninglabs/aperture/mint"
+)
+
+// etcdKeyPrefix is the etcd key prefix under which all LSAT secrets are
+// stored.
+const etcdKeyPrefix = "aperture/secrets/"
+
+// EtcdStore is a mint.SecretStore backed by an etcd cluster, suitable for an
+// HA deployment where multiple aperture instances share state.
+type EtcdStore struct {
+	client *clientv3.Client
+}
+
+// NewEtcdStore creates a new EtcdStore using the given client connection.
+func NewEtcdStore(client *clientv3.Client) *EtcdStore {
+	return &EtcdStore{client: client}
+}
+
+func etcdKey(id [sha256.Size]byte) string {
+	return etcdKeyPrefix + hex.EncodeToString(id[:])
+}
+
+// NewSecret generates a new random secret for the given identifier hash and
+// persists it to etcd.
+func (s *EtcdStore) NewSecret(ctx context.Context,
+	id [sha256.Size]byte) ([lsat.SecretSize]byte, error) {
+
+	var secret [lsat.SecretSize]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return secret, fmt.Errorf("unable to generate secret: %v", err)
+	}
+
+	_, err := s.client.Put(ctx, etcdKey(id), hex.EncodeToString(secret[:]))
+	if err != nil {
+		return secret, fmt.Errorf("unable to store secret: %v", err)
+	}
+
+	return secret, nil
+}
+
+// GetSecret retrieves the secret for the given identifier hash.
+func (s *EtcdStore) GetSecret(ctx context.Context,
+	id [sha256.Size]byte) ([lsat.SecretSize]byte, error) {
+
+	var secret [lsat.SecretSize]byte
+
+	resp, err := s.client.Get(ctx, etcdKey(id))
+	if err != nil {
+		return secret, fmt.Errorf("unable to fetch secret: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return secret, mint.ErrSecretNotFound
+	}
+
+	raw, err := hex.DecodeString(string(resp.Kvs[0].Value))
+	if err != nil {
+		return secret, fmt.Errorf("unable to decode secret: %v", err)
+	}
+	copy(secret[:], raw)
+
+	return secret, nil
+}
+
+// PutSecret writes an already-derived secret value for the given identifier
+// hash, overwriting any existing value. It implements the secretWriter
+// interface used by the migration tooling.
+func (s *EtcdStore) PutSecret(ctx context.Context, id [sha256.Size]byte,
+	secret [lsat.SecretSize]byte) error {
+
+	_, err := s.client.Put(ctx, etcdKey(id), hex.EncodeToString(secret[:]))
+	if err != nil {
+		return fmt.Errorf("unable to store secret: %v", err)
+	}
+	return nil
+}
+
+// RevokeSecret deletes the secret for the given identifier hash, if any.
+func (s *EtcdStore) RevokeSecret(ctx context.Context, id [sha256.Size]byte) error {
+	_, err := s.client.Delete(ctx, etcdKey(id))
+	if err != nil {
+		return fmt.Errorf("unable to revoke secret: %v", err)
+	}
+	return nil
+}
+
+// ListSecretIDs returns the identifier hash of every secret currently
+// persisted in etcd. It implements the Enumerable interface.
+func (s *EtcdStore) ListSecretIDs(ctx context.Context) ([][32]byte, error) {
+	resp, err := s.client.Get(
+		ctx, etcdKeyPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list secrets: %v", err)
+	}
+
+	ids := make([][32]byte, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		raw, err := hex.DecodeString(string(kv.Key)[len(etcdKeyPrefix):])
+		if err != nil || len(raw) != 32 {
+			continue
+		}
+		var id [32]byte
+		copy(id[:], raw)
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
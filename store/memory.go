@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightninglabs/aperture/mint"
+)
+
+// MemoryStore is a mint.SecretStore that keeps all secrets purely in
+// memory. It's only suitable for testing and development, since secrets do
+// not survive a restart.
+type MemoryStore struct {
+	mu      sync.Mutex
+	secrets map[[sha256.Size]byte][lsat.SecretSize]byte
+}
+
+// NewMemoryStore creates a new, empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		secrets: make(map[[sha256.Size]byte][lsat.SecretSize]byte),
+	}
+}
+
+// NewSecret generates a new random secret for the given identifier hash and
+// stores it in memory.
+func (s *MemoryStore) NewSecret(_ context.Context,
+	id [sha256.Size]byte) ([lsat.SecretSize]byte, error) {
+
+	var secret [lsat.SecretSize]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return secret, fmt.Errorf("unable to generate secret: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[id] = secret
+
+	return secret, nil
+}
+
+// GetSecret retrieves the secret for the given identifier hash.
+func (s *MemoryStore) GetSecret(_ context.Context,
+	id [sha256.Size]byte) ([lsat.SecretSize]byte, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, ok := s.secrets[id]
+	if !ok {
+		return secret, mint.ErrSecretNotFound
+	}
+
+	return secret, nil
+}
+
+// PutSecret writes an already-derived secret value for the given identifier
+// hash, overwriting any existing value. It implements the secretWriter
+// interface used by the migration tooling.
+func (s *MemoryStore) PutSecret(_ context.Context, id [sha256.Size]byte,
+	secret [lsat.SecretSize]byte) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[id] = secret
+	return nil
+}
+
+// RevokeSecret deletes the secret for the given identifier hash, if any.
+func (s *MemoryStore) RevokeSecret(_ context.Context, id [sha256.Size]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.secrets, id)
+	return nil
+}
+
+// ListSecretIDs returns the identifier hash of every secret currently held
+// in memory. It implements the Enumerable interface.
+func (s *MemoryStore) ListSecretIDs(_ context.Context) ([][32]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([][32]byte, 0, len(s.secrets))
+	for id := range s.secrets {
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lightninglabs/aperture/mint"
+)
+
+// Migrate copies every secret known to src into dst. src must implement
+// Enumerable so the set of identifier hashes to copy can be discovered; an
+// error is returned otherwise. Secrets that already exist in dst are left
+// untouched rather than overwritten, so Migrate can safely be re-run to
+// pick up any secrets added to src after a previous run.
+func Migrate(ctx context.Context, src mint.SecretStore, dst mint.SecretStore) (int, error) {
+	enumerable, ok := src.(Enumerable)
+	if !ok {
+		return 0, fmt.Errorf("source store %T does not support "+
+			"enumeration, cannot migrate", src)
+	}
+
+	ids, err := enumerable.ListSecretIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to list secrets in source "+
+			"store: %v", err)
+	}
+
+	var copied int
+	for _, id := range ids {
+		if _, err := dst.GetSecret(ctx, id); err == nil {
+			// Already present in the destination, nothing to do.
+			continue
+		}
+
+		secret, err := src.GetSecret(ctx, id)
+		if err != nil {
+			return copied, fmt.Errorf("unable to read secret "+
+				"%x from source: %v", id, err)
+		}
+
+		if err := copySecret(ctx, dst, id, secret); err != nil {
+			return copied, fmt.Errorf("unable to write secret "+
+				"%x to destination: %v", id, err)
+		}
+
+		copied++
+	}
+
+	return copied, nil
+}
+
+// copySecret writes an already-derived secret value into dst. Stores only
+// expose NewSecret (which generates a fresh random value), so backends used
+// as a migration destination must additionally implement secretWriter to
+// preserve the exact secret bytes from the source store.
+func copySecret(ctx context.Context, dst mint.SecretStore, id [32]byte,
+	secret [32]byte) error {
+
+	writer, ok := dst.(secretWriter)
+	if !ok {
+		return fmt.Errorf("destination store %T cannot preserve "+
+			"existing secret values", dst)
+	}
+
+	return writer.PutSecret(ctx, id, secret)
+}
+
+// secretWriter is implemented by stores that support writing a specific,
+// already-derived secret value rather than always generating a fresh
+// random one. It is used exclusively by the migration tooling.
+type secretWriter interface {
+	PutSecret(ctx context.Context, id [32]byte, secret [32]byte) error
+}
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	// Register the Postgres driver under the "postgres" name.
+	_ "github.com/lib/pq"
+
+	"github.com/lightninglabs/aperture/lsat"
+	"github.com/lightninglabs/aperture/mint"
+)
+
+// createSecretsTableSQL creates the table used to persist secrets if it
+// doesn't already exist. It's applied automatically the first time a
+// PostgresStore is opened.
+const createSecretsTableSQL = `
+CREATE TABLE IF NOT EXISTS lsat_secrets (
+	id_hash BYTEA PRIMARY KEY,
+	secret  BYTEA NOT NULL
+);`
+
+// PostgresStore is a mint.SecretStore backed by a Postgres database,
+// suitable for a hosted deployment that already operates Postgres and
+// doesn't want to also run etcd.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore opens a connection to the Postgres database described by
+// dsn and ensures the schema it needs exists.
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to postgres: %v", err)
+	}
+
+	if _, err := db.Exec(createSecretsTableSQL); err != nil {
+		return nil, fmt.Errorf("unable to create schema: %v", err)
+	}
+
+	return &PostgresStore{db: db}, nil
+}
+
+// Close closes the underlying database connection pool.
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// NewSecret generates a new random secret for the given identifier hash and
+// persists it to Postgres.
+func (s *PostgresStore) NewSecret(ctx context.Context,
+	id [sha256.Size]byte) ([lsat.SecretSize]byte, error) {
+
+	var secret [lsat.SecretSize]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return secret, fmt.Errorf("unable to generate secret: %v", err)
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO lsat_secrets (id_hash, secret) VALUES ($1, $2)
+		ON CONFLICT (id_hash) DO UPDATE SET secret = EXCLUDED.secret`,
+		id[:], secret[:],
+	)
+	if err != nil {
+		return secret, fmt.Errorf("unable to store secret: %v", err)
+	}
+
+	return secret, nil
+}
+
+// GetSecret retrieves the secret for the given identifier hash.
+func (s *PostgresStore) GetSecret(ctx context.Context,
+	id [sha256.Size]byte) ([lsat.SecretSize]byte, error) {
+
+	var secret [lsat.SecretSize]byte
+
+	row := s.db.QueryRowContext(
+		ctx, `SELECT secret FROM lsat_secrets WHERE id_hash = $1`, id[:],
+	)
+
+	var raw []byte
+	switch err := row.Scan(&raw); {
+	case errors.Is(err, sql.ErrNoRows):
+		return secret, mint.ErrSecretNotFound
+	case err != nil:
+		return secret, fmt.Errorf("unable to fetch secret: %v", err)
+	}
+
+	copy(secret[:], raw)
+	return secret, nil
+}
+
+// PutSecret writes an already-derived secret value for the given identifier
+// hash, overwriting any existing value. It implements the secretWriter
+// interface used by the migration tooling.
+func (s *PostgresStore) PutSecret(ctx context.Context, id [sha256.Size]byte,
+	secret [lsat.SecretSize]byte) error {
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO lsat_secrets (id_hash, secret) VALUES ($1, $2)
+		ON CONFLICT (id_hash) DO UPDATE SET secret = EXCLUDED.secret`,
+		id[:], secret[:],
+	)
+	if err != nil {
+		return fmt.Errorf("unable to store secret: %v", err)
+	}
+	return nil
+}
+
+// RevokeSecret deletes the secret for the given identifier hash, if any.
+func (s *PostgresStore) RevokeSecret(ctx context.Context, id [sha256.Size]byte) error {
+	_, err := s.db.ExecContext(
+		ctx, `DELETE FROM lsat_secrets WHERE id_hash = $1`, id[:],
+	)
+	if err != nil {
+		return fmt.Errorf("unable to revoke secret: %v", err)
+	}
+	return nil
+}
+
+// ListSecretIDs returns the identifier hash of every secret currently
+// persisted in Postgres. It implements the Enumerable interface.
+func (s *PostgresStore) ListSecretIDs(ctx context.Context) ([][32]byte, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id_hash FROM lsat_secrets`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list secrets: %v", err)
+	}
+	defer rows.Close()
+
+	var ids [][32]byte
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("unable to scan row: %v", err)
+		}
+		if len(raw) != 32 {
+			continue
+		}
+		var id [32]byte
+		copy(id[:], raw)
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
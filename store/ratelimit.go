@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	bbolt "go.etcd.io/bbolt"
+)
+
+// rateLimitBucket is the bbolt bucket used to persist rate limit windows.
+var rateLimitBucket = []byte("ratelimits")
+
+// window is the persisted state of a single rate limit bucket.
+type window struct {
+	start time.Time
+	count int
+}
+
+// MemoryRateLimiter is a mint.RateLimiter that keeps all counters in
+// memory. Counters do not survive a restart.
+type MemoryRateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]window
+}
+
+// NewMemoryRateLimiter creates a new, empty MemoryRateLimiter.
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{windows: make(map[string]window)}
+}
+
+// Allow implements mint.RateLimiter.
+func (l *MemoryRateLimiter) Allow(_ context.Context, key string, limit int,
+	windowSize time.Duration) (bool, error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= windowSize {
+		w = window{start: now, count: 0}
+	}
+
+	w.count++
+	l.windows[key] = w
+
+	return w.count <= limit, nil
+}
+
+// BboltRateLimiter is a mint.RateLimiter backed by a bbolt database,
+// allowing rate limit counters to survive a restart. It keeps its counters in
+// a bucket of their own within the database it's given. BboltStore does not
+// expose its database handle, so a BboltRateLimiter can't share the file a
+// BboltStore has open.
+type BboltRateLimiter struct {
+	db *bbolt.DB
+}
+
+// NewBboltRateLimiter creates a BboltRateLimiter using db, creating the
+// bucket it needs if it doesn't already exist.
+func NewBboltRateLimiter(db *bbolt.DB) (*BboltRateLimiter, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(rateLimitBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create bucket: %v", err)
+	}
+
+	return &BboltRateLimiter{db: db}, nil
+}
+
+// Allow implements mint.RateLimiter.
+func (l *BboltRateLimiter) Allow(_ context.Context, key string, limit int,
+	windowSize time.Duration) (bool, error) {
+
+	var allowed bool
+	now := time.Now()
+
+	err := l.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(rateLimitBucket)
+
+		var w window
+		if raw := bucket.Get([]byte(key)); raw != nil {
+			w = decodeWindow(raw)
+		}
+
+		if now.Sub(w.start) >= windowSize {
+			w = window{start: now, count: 0}
+		}
+		w.count++
+		allowed = w.count <= limit
+
+		return bucket.Put([]byte(key), encodeWindow(w))
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to update rate limit: %v", err)
+	}
+
+	return allowed, nil
+}
+
+func encodeWindow(w window) []byte {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], uint64(w.start.Unix()))
+	binary.BigEndian.PutUint64(buf[8:], uint64(w.count))
+	return buf
+}
+
+func decodeWindow(raw []byte) window {
+	return window{
+		start: time.Unix(int64(binary.BigEndian.Uint64(raw[:8])), 0),
+		count: int(binary.BigEndian.Uint64(raw[8:])),
+	}
+}
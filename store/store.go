@@ -0,0 +1,117 @@
+// Package store provides selectable implementations of the mint.SecretStore
+// interface, allowing an Aperture instance to be configured to persist LSAT
+// secrets in etcd, bbolt, Postgres, or purely in memory, depending on the
+// scale and availability requirements of the deployment.
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/lightninglabs/aperture/mint"
+)
+
+// Backend identifies which storage engine backs a mint.SecretStore.
+type Backend string
+
+const (
+	// BackendEtcd stores secrets in an etcd cluster. This is the right
+	// choice for an HA deployment with multiple aperture instances
+	// sharing state.
+	BackendEtcd Backend = "etcd"
+
+	// BackendBbolt stores secrets in a local bbolt database file. This is
+	// the right choice for a small, single-binary deployment that
+	// doesn't need to share state with other instances.
+	BackendBbolt Backend = "bbolt"
+
+	// BackendPostgres stores secrets in a Postgres database. This is the
+	// right choice for a hosted deployment that already has a Postgres
+	// instance available and wants to avoid operating etcd.
+	BackendPostgres Backend = "postgres"
+
+	// BackendMemory stores secrets purely in memory. This is only
+	// suitable for testing and development; secrets do not survive a
+	// restart.
+	BackendMemory Backend = "memory"
+)
+
+// EtcdConfig houses the settings needed to connect to an etcd cluster.
+type EtcdConfig struct {
+	Host     string `long:"host" description:"etcd instance address"`
+	User     string `long:"user" description:"etcd user name"`
+	Password string `long:"password" description:"etcd password"`
+}
+
+// BboltConfig houses the settings needed to open a local bbolt database.
+type BboltConfig struct {
+	DatabasePath string `long:"dir" description:"directory holding the bbolt database file"`
+}
+
+// PostgresConfig houses the settings needed to connect to a Postgres
+// database.
+type PostgresConfig struct {
+	DSN string `long:"dsn" description:"Postgres connection string"`
+}
+
+// Config selects and configures the storage.backend used to persist LSAT
+// secrets.
+type Config struct {
+	// Backend is the storage engine to use.
+	Backend Backend `long:"backend" description:"storage backend to use: etcd|bbolt|postgres|memory"`
+
+	Etcd     *EtcdConfig     `group:"etcd" namespace:"etcd"`
+	Bbolt    *BboltConfig    `group:"bbolt" namespace:"bbolt"`
+	Postgres *PostgresConfig `group:"postgres" namespace:"postgres"`
+}
+
+// NewStore constructs the mint.SecretStore selected by cfg.Backend.
+func NewStore(cfg *Config) (mint.SecretStore, error) {
+	switch cfg.Backend {
+	case BackendEtcd:
+		if cfg.Etcd == nil {
+			return nil, fmt.Errorf("etcd config required for " +
+				"etcd backend")
+		}
+		client, err := clientv3.New(clientv3.Config{
+			Endpoints: []string{cfg.Etcd.Host},
+			Username:  cfg.Etcd.User,
+			Password:  cfg.Etcd.Password,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to connect to etcd: %v", err)
+		}
+		return NewEtcdStore(client), nil
+
+	case BackendBbolt:
+		if cfg.Bbolt == nil {
+			return nil, fmt.Errorf("bbolt config required for " +
+				"bbolt backend")
+		}
+		return NewBboltStore(cfg.Bbolt.DatabasePath)
+
+	case BackendPostgres:
+		if cfg.Postgres == nil {
+			return nil, fmt.Errorf("postgres config required " +
+				"for postgres backend")
+		}
+		return NewPostgresStore(cfg.Postgres.DSN)
+
+	case BackendMemory:
+		return NewMemoryStore(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// Enumerable is implemented by SecretStore backends that are able to list
+// the identifier hashes of every secret they currently hold. It is used by
+// the migration tooling to copy secrets from one backend to another without
+// requiring the caller to already know every identifier hash up front.
+type Enumerable interface {
+	// ListSecretIDs returns the identifier hash of every secret
+	// currently persisted by the store.
+	ListSecretIDs(ctx context.Context) ([][32]byte, error)
+}